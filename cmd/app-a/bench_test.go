@@ -0,0 +1,78 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	otelSetup "go-observability-lab/internal/otel"
+)
+
+// newStubAppB sobe um App B sem instrumentação, que responde na hora, para que
+// os benchmarks meçam só o custo do lado de App A.
+func newStubAppB(b *testing.B) *httptest.Server {
+	b.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"service":"app-b","chain":["app-b"]}`))
+	}))
+	b.Cleanup(srv.Close)
+	return srv
+}
+
+// discardStdout recria o logger de App A com a saída padrão descartada, para
+// que os benchmarks não meçam (nem imprimam) um log por requisição; o handler
+// do OTel continua no caminho, como em produção.
+func discardStdout(b *testing.B) {
+	b.Helper()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatalf("Erro ao abrir %s: %v", os.DevNull, err)
+	}
+	stdout, oldLogger := os.Stdout, logger
+	os.Stdout = devNull
+	logger = otelSetup.NewLogger(serviceName)
+	b.Cleanup(func() {
+		os.Stdout, logger = stdout, oldLogger
+		devNull.Close()
+	})
+}
+
+// benchmarkHandler executa requisições GET / em handler, descartando os spans
+// gravados periodicamente para que a memória do exporter em memória não
+// distorça a medição.
+func benchmarkHandler(b *testing.B, handler http.Handler) {
+	b.Setenv("APP_B_URL", newStubAppB(b).URL)
+	discardStdout(b)
+	providers.Reset()
+	b.ReportAllocs()
+
+	i := 0
+	for b.Loop() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			b.Fatalf("status = %d, esperado %d", rec.Code, http.StatusOK)
+		}
+
+		if i++; i%1000 == 0 {
+			b.StopTimer()
+			providers.Reset()
+			b.StartTimer()
+		}
+	}
+}
+
+// BenchmarkHandleRoot mede só o handler, com a chamada a App B.
+func BenchmarkHandleRoot(b *testing.B) {
+	benchmarkHandler(b, http.HandlerFunc(handleRoot))
+}
+
+// BenchmarkHTTPHandler mede o handler com toda a cadeia de middlewares e o
+// otelhttp; a diferença para BenchmarkHandleRoot é o custo da instrumentação.
+func BenchmarkHTTPHandler(b *testing.B) {
+	benchmarkHandler(b, newHTTPHandler(&otelSetup.Providers{}))
+}
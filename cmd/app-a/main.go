@@ -2,26 +2,39 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io"
+	"fmt"
 	"log"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
 
+	"go-observability-lab/internal/admin"
+	"go-observability-lab/internal/attrs"
+	"go-observability-lab/internal/buildinfo"
+	"go-observability-lab/internal/config"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/faults"
+	"go-observability-lab/internal/httpclient"
+	"go-observability-lab/internal/middleware"
 	otelSetup "go-observability-lab/internal/otel"
+	"go-observability-lab/internal/server"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const serviceName = "app-a"
 
-var tracer = otel.Tracer(serviceName)
+var (
+	tracer = otel.Tracer(serviceName)
+	logger = otelSetup.NewLogger(serviceName)
+)
 
 func main() {
 	if err := run(); err != nil {
@@ -30,51 +43,32 @@ func main() {
 }
 
 func run() error {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	// Depois do primeiro sinal, um segundo encerra a app imediatamente
+	context.AfterFunc(ctx, stop)
 
 	// Configura OpenTelemetry
-	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if otlpEndpoint == "" {
-		otlpEndpoint = "localhost:4317"
+	cfg, err := config.Load(serviceName)
+	if err != nil {
+		return err
 	}
 
-	otelShutdown, err := otelSetup.SetupOTelSDK(ctx, serviceName, otlpEndpoint)
-
+	providers, err := otelSetup.SetupProviders(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = errors.Join(err, otelShutdown(context.Background()))
-	}()
 
 	// Servidor HTTP
-	srv := &http.Server{
-		Addr:         ":8080",
-		BaseContext:  func(_ net.Listener) context.Context { return ctx },
-		ReadTimeout:  time.Second,
-		WriteTimeout: 10 * time.Second,
-		Handler:      newHTTPHandler(),
-	}
-
-	srvErr := make(chan error, 1)
-	go func() {
-		log.Printf("🚀 %s iniciado na porta 8080", serviceName)
-		srvErr <- srv.ListenAndServe()
-	}()
-
-	select {
-	case err = <-srvErr:
-		return err
-	case <-ctx.Done():
-		stop()
+	addr, err := server.ListenAddr(":8080")
+	if err != nil {
+		return errors.Join(err, providers.Shutdown(context.Background()))
 	}
 
-	err = srv.Shutdown(context.Background())
-	return err
+	return server.Run(ctx, serviceName, addr, newHTTPHandler(providers), providers, cfg.ShutdownTimeout)
 }
 
-func newHTTPHandler() http.Handler {
+func newHTTPHandler(providers *otelSetup.Providers) http.Handler {
 	mux := http.NewServeMux()
 
 	handleFunc := func(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) {
@@ -84,20 +78,64 @@ func newHTTPHandler() http.Handler {
 
 	handleFunc("/", handleRoot)
 	handleFunc("/health", handleHealth)
+	handleFunc("/ready", server.HandleReady)
+	handleFunc("/version", handleVersion)
+	handleFunc("/fail", handleFail)
+	handleFunc("/debug/trace", handleDebugTrace)
+	handleFunc("/admin/sampling", admin.HandleSampling)
+	handleFunc("/admin/flush", admin.HandleFlush(providers))
+	handleFunc("/batch", handleBatch)
+	handleFunc("/enqueue", handleEnqueue)
+
+	if providers.MetricsHandler != nil {
+		mux.Handle(providers.MetricsPath, providers.MetricsHandler)
+	}
 
-	return otelhttp.NewHandler(mux, "/")
+	var handler http.Handler = mux
+	// O /batch faz várias chamadas em sequência e tem um limite próprio
+	handler = middleware.SLOMetrics(serviceName, map[string]time.Duration{
+		"/batch": 5 * time.Second,
+	})(handler)
+	handler = middleware.Synthetic(middleware.SyntheticRulesFromEnv())(handler)
+	handler = middleware.BodySize()(handler)
+	handler = middleware.Goroutines(serviceName)(handler)
+	handler = middleware.CaptureHeaders()(handler)
+	handler = middleware.ClientInfo()(handler)
+	handler = middleware.ChainDepth()(handler)
+	handler = middleware.RequestID(handler)
+	handler = middleware.TraceID(handler)
+	handler = middleware.Recover(serviceName)(handler)
+
+	handler = otelhttp.NewHandler(handler, "/", otelSetup.ServerHandlerOptions()...)
+	// Fora do otelhttp, para decidir a amostragem forçada antes do span do
+	// servidor; só chamadores com o token administrativo podem forçá-la
+	return middleware.ForceTrace(admin.Authorized)(handler)
 }
 
 func handleRoot(w http.ResponseWriter, r *http.Request) {
-	ctx, span := tracer.Start(r.Context(), "handleRoot")
+	ctx, span := otelSetup.StartServerSpan(r.Context(), tracer, r, "handleRoot")
 	defer span.End()
 
-	span.SetAttributes(
-		attribute.String("http.method", r.Method),
-		attribute.String("http.path", r.URL.Path),
-	)
+	// Requisições lentas ou com erro são priorizadas pelo tail sampling
+	start := time.Now()
+	failed := false
+	defer func() {
+		otelSetup.MarkSamplingPriority(span, time.Since(start), failed)
+	}()
 
-	log.Printf("[%s] Recebida requisição em /", serviceName)
+	logger.InfoContext(ctx, "Recebida requisição", "method", r.Method, "path", r.URL.Path)
+
+	if faults.ShouldFail(r) {
+		failed = true
+		faults.Fail(w, r, span)
+		return
+	}
+
+	// Propaga o tier do usuário para App B e App C via baggage
+	ctx, err := otelSetup.WithBaggageValue(ctx, "user.tier", "premium")
+	if err != nil {
+		logger.WarnContext(ctx, "Erro ao definir baggage", "error", err)
+	}
 
 	// Chama App B
 	appBURL := os.Getenv("APP_B_URL")
@@ -105,21 +143,35 @@ func handleRoot(w http.ResponseWriter, r *http.Request) {
 		appBURL = "http://localhost:8081"
 	}
 
+	if otelSetup.Cancelled(ctx, span) {
+		return
+	}
+
+	target := trace.WithAttributes(attrs.Target("app-b"))
+	span.AddEvent("downstream.call.start", target)
 	result, err := callAppB(ctx, appBURL)
+	span.AddEvent("downstream.call.end", target)
 	if err != nil {
+		failed = true
 		span.RecordError(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		span.SetStatus(codes.Error, err.Error())
+		server.WriteErrorJSON(w, r, httpclient.ErrorStatus(err), err)
 		return
 	}
 
+	chain := httpclient.Chain(serviceName, result)
 	response := map[string]interface{}{
 		"service": serviceName,
 		"message": "Chamou App B com sucesso",
+		"chain":   chain,
 		"result":  result,
 	}
 
+	span.SetAttributes(attrs.Chain(chain))
+	span.SetStatus(codes.Ok, "")
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	httpclient.EncodeJSON(ctx, w, response)
 }
 
 func callAppB(ctx context.Context, url string) (map[string]interface{}, error) {
@@ -127,43 +179,192 @@ func callAppB(ctx context.Context, url string) (map[string]interface{}, error) {
 	defer span.End()
 
 	span.SetAttributes(
-		attribute.String("app.b.url", url),
+		attrs.AppBURL(url),
 	)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url+"/", nil)
-	if err != nil {
+	var result map[string]interface{}
+	if err := httpclient.CallJSON(ctx, url+"/", &result, httpclient.WithTarget("app-b")); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	client := http.Client{
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
-		Timeout:   5 * time.Second,
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
+
+// maxBatchCount limita quantas chamadas um único /batch pode disparar.
+const maxBatchCount = 20
+
+// handleBatch dispara ?count=N chamadas ao App B. Cada item roda em um trace
+// próprio, correlacionado ao span do lote por um span link.
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "handleBatch")
+	defer span.End()
+
+	count := 1
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxBatchCount {
+			err := fmt.Errorf("count deve ser um inteiro entre 1 e %d", maxBatchCount)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			server.WriteErrorJSON(w, r, http.StatusBadRequest, err)
+			return
+		}
+		count = n
 	}
+	span.SetAttributes(attrs.BatchCount(count))
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	appBURL := os.Getenv("APP_B_URL")
+	if appBURL == "" {
+		appBURL = "http://localhost:8081"
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	batch := []trace.SpanContext{span.SpanContext()}
+	results := make([]map[string]interface{}, 0, count)
+	failures := 0
+	for i := range count {
+		itemCtx, itemSpan := otelSetup.StartLinkedSpan(ctx, tracer, "batchItem", batch,
+			trace.WithNewRoot(),
+			trace.WithAttributes(attrs.BatchIndex(i)),
+		)
+
+		result, err := callAppB(itemCtx, appBURL)
+		if err != nil {
+			failures++
+			itemSpan.RecordError(err)
+			itemSpan.SetStatus(codes.Error, err.Error())
+			result = map[string]interface{}{"error": err.Error()}
+		}
+		itemSpan.End()
+
+		results = append(results, result)
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
+	span.SetAttributes(attrs.BatchFailures(failures))
+	span.SetStatus(codes.Ok, "")
+
+	response := map[string]interface{}{
+		"service": serviceName,
+		"count":   count,
+		"results": results,
 	}
 
-	span.SetAttributes(
-		attribute.Int("http.status_code", resp.StatusCode),
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(ctx, w, response)
+}
+
+// message simula uma mensagem de fila: o contexto de trace viaja nos headers,
+// e não em uma requisição HTTP.
+type message struct {
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// maxEnqueueBytes limita o corpo aceito por POST /enqueue.
+const maxEnqueueBytes = 64 << 10
+
+// handleEnqueue "publica" uma mensagem com o contexto de trace e a consome em
+// seguida a partir de um contexto vazio, mostrando que a propagação funciona
+// fora do net/http. Em um POST, o corpo da requisição vira o corpo da mensagem.
+func handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracer.Start(r.Context(), "publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs.MessagingSystem("in-memory")),
 	)
+	defer span.End()
 
-	return result, nil
+	msg := message{
+		Headers: otelSetup.InjectMap(ctx),
+		Body:    "pedido criado",
+	}
+
+	if r.Method == http.MethodPost {
+		body, err := httpclient.ReadBody(ctx, w, r, maxEnqueueBytes)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			server.WriteErrorJSON(w, r, httpclient.ErrorStatus(err), err)
+			return
+		}
+		if len(body) > 0 {
+			msg.Body = string(body)
+		}
+	}
+
+	consume(msg)
+
+	span.SetStatus(codes.Ok, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(ctx, w, map[string]interface{}{
+		"service": serviceName,
+		"message": msg,
+	})
+}
+
+// consume processa msg continuando o trace propagado nos seus headers.
+func consume(msg message) {
+	ctx := otelSetup.ExtractMap(context.Background(), msg.Headers)
+	ctx, span := tracer.Start(ctx, "consume",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs.MessagingSystem("in-memory")),
+	)
+	defer span.End()
+
+	logger.InfoContext(ctx, "Mensagem consumida", "body", msg.Body)
+}
+
+// handleFail sempre falha, para demonstrar spans de erro e alertas.
+func handleFail(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "handleFail")
+	defer span.End()
+
+	faults.Fail(w, r, span)
+}
+
+// handleDebugTrace retorna o trace ID da requisição, para depuração manual.
+func handleDebugTrace(w http.ResponseWriter, r *http.Request) {
+	sc := trace.SpanContextFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(r.Context(), w, map[string]string{
+		"service":  serviceName,
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	})
+}
+
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(r.Context(), w, buildinfo.Get(serviceName))
 }
 
+// handleHealth responde OK sem verificar nada, para não gerar verificações em
+// cascata nas sondas comuns. Com ?deep=1, verifica também os serviços
+// seguintes.
 func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	if r.URL.Query().Get("deep") != "1" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
+
+	ctx, span := tracer.Start(r.Context(), "handleHealth")
+	defer span.End()
+
+	health := httpclient.DeepHealth(ctx, serviceName, map[string]string{
+		"app-b": env.String("APP_B_URL", "http://localhost:8081"),
+	})
+
+	status := http.StatusOK
+	if health.Status != httpclient.HealthOK {
+		status = http.StatusServiceUnavailable
+		span.SetStatus(codes.Error, "dependência degradada")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	httpclient.EncodeJSON(ctx, w, health)
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go-observability-lab/internal/httpclient"
+	otelSetup "go-observability-lab/internal/otel"
+	"go-observability-lab/internal/otel/testutil"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var providers *testutil.TestProviders
+
+func TestMain(m *testing.M) {
+	providers = testutil.NewTestProviders()
+	code := m.Run()
+	providers.Shutdown(context.Background())
+	os.Exit(code)
+}
+
+// newDownstream sobe um serviço instrumentado como as apps do lab: um span
+// handleRoot sob o span de servidor do otelhttp e, opcionalmente, um span
+// callNext envolvendo a chamada ao próximo serviço.
+func newDownstream(t *testing.T, service, callNext, nextURL string) *httptest.Server {
+	t.Helper()
+
+	tracer := otel.Tracer(service)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracer.Start(r.Context(), "handleRoot")
+		defer span.End()
+
+		response := map[string]interface{}{"service": service}
+		if callNext != "" {
+			ctx, callSpan := tracer.Start(ctx, callNext)
+			var result map[string]interface{}
+			err := httpclient.CallJSON(ctx, nextURL+"/", &result)
+			callSpan.End()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			response["result"] = result
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		httpclient.EncodeJSON(ctx, w, response)
+	})
+
+	srv := httptest.NewServer(otelhttp.NewHandler(handler, "/"))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHandleRootSpanHierarchy(t *testing.T) {
+	providers.Reset()
+
+	appC := newDownstream(t, "app-c", "", "")
+	appB := newDownstream(t, "app-b", "callAppC", appC.URL)
+	t.Setenv("APP_B_URL", appB.URL)
+
+	appA := httptest.NewServer(newHTTPHandler(&otelSetup.Providers{}))
+	defer appA.Close()
+
+	resp, err := http.Get(appA.URL + "/")
+	if err != nil {
+		t.Fatalf("GET /: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, esperado %d", resp.StatusCode, http.StatusOK)
+	}
+
+	spans := providers.Spans(context.Background())
+
+	rootA := findSpan(t, spans, "app-a", "handleRoot")
+	callB := findSpan(t, spans, "app-a", "callAppB")
+	rootB := findSpan(t, spans, "app-b", "handleRoot")
+	callC := findSpan(t, spans, "app-b", "callAppC")
+	rootC := findSpan(t, spans, "app-c", "handleRoot")
+
+	traceID := rootA.SpanContext.TraceID()
+	for _, s := range []tracetest.SpanStub{callB, rootB, callC, rootC} {
+		if got := s.SpanContext.TraceID(); got != traceID {
+			t.Errorf("%s/%s: trace ID = %s, esperado %s", s.InstrumentationScope.Name, s.Name, got, traceID)
+		}
+	}
+
+	assertParent(t, spans, callB, rootA, false)
+	assertParent(t, spans, rootB, callB, true)
+	assertParent(t, spans, callC, rootB, false)
+	assertParent(t, spans, rootC, callC, true)
+}
+
+// findSpan retorna o span chamado name criado pelo tracer scope.
+func findSpan(t *testing.T, spans tracetest.SpanStubs, scope, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, s := range spans {
+		if s.InstrumentationScope.Name == scope && s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("span %s/%s não encontrado", scope, name)
+	return tracetest.SpanStub{}
+}
+
+// assertParent verifica que ancestor está na cadeia de pais de child. Com
+// remote, exige também que a cadeia cruze uma fronteira HTTP, ou seja, que
+// algum span intermediário tenha sido criado a partir de um contexto remoto.
+func assertParent(t *testing.T, spans tracetest.SpanStubs, child, ancestor tracetest.SpanStub, remote bool) {
+	t.Helper()
+
+	byID := make(map[trace.SpanID]tracetest.SpanStub, len(spans))
+	for _, s := range spans {
+		byID[s.SpanContext.SpanID()] = s
+	}
+
+	crossed := false
+	current := child
+	for current.Parent.IsValid() {
+		crossed = crossed || current.Parent.IsRemote()
+		if current.Parent.SpanID() == ancestor.SpanContext.SpanID() {
+			if remote && !crossed {
+				t.Errorf("%s → %s: esperado um salto remoto entre os spans", ancestor.Name, child.Name)
+			}
+			return
+		}
+		parent, ok := byID[current.Parent.SpanID()]
+		if !ok {
+			break
+		}
+		current = parent
+	}
+	t.Errorf("%s/%s não é descendente de %s/%s",
+		child.InstrumentationScope.Name, child.Name, ancestor.InstrumentationScope.Name, ancestor.Name)
+}
@@ -2,26 +2,38 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io"
 	"log"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"go-observability-lab/internal/admin"
+	"go-observability-lab/internal/attrs"
+	"go-observability-lab/internal/buildinfo"
+	"go-observability-lab/internal/config"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/faults"
+	"go-observability-lab/internal/httpclient"
+	"go-observability-lab/internal/middleware"
 	otelSetup "go-observability-lab/internal/otel"
+	"go-observability-lab/internal/server"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const serviceName = "app-b"
 
-var tracer = otel.Tracer(serviceName)
+var (
+	tracer = otel.Tracer(serviceName)
+	logger = otelSetup.NewLogger(serviceName)
+)
 
 func main() {
 	if err := run(); err != nil {
@@ -30,48 +42,30 @@ func main() {
 }
 
 func run() error {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	// Depois do primeiro sinal, um segundo encerra a app imediatamente
+	context.AfterFunc(ctx, stop)
 
-	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if otlpEndpoint == "" {
-		otlpEndpoint = "localhost:4317"
-	}
-
-	otelShutdown, err := otelSetup.SetupOTelSDK(ctx, serviceName, otlpEndpoint)
+	cfg, err := config.Load(serviceName)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = errors.Join(err, otelShutdown(context.Background()))
-	}()
 
-	srv := &http.Server{
-		Addr:         ":8081",
-		BaseContext:  func(_ net.Listener) context.Context { return ctx },
-		ReadTimeout:  time.Second,
-		WriteTimeout: 10 * time.Second,
-		Handler:      newHTTPHandler(),
+	providers, err := otelSetup.SetupProviders(ctx, cfg)
+	if err != nil {
+		return err
 	}
 
-	srvErr := make(chan error, 1)
-	go func() {
-		log.Printf("🚀 %s iniciado na porta 8081", serviceName)
-		srvErr <- srv.ListenAndServe()
-	}()
-
-	select {
-	case err = <-srvErr:
-		return err
-	case <-ctx.Done():
-		stop()
+	addr, err := server.ListenAddr(":8081")
+	if err != nil {
+		return errors.Join(err, providers.Shutdown(context.Background()))
 	}
 
-	err = srv.Shutdown(context.Background())
-	return err
+	return server.Run(ctx, serviceName, addr, newHTTPHandler(providers), providers, cfg.ShutdownTimeout)
 }
 
-func newHTTPHandler() http.Handler {
+func newHTTPHandler(providers *otelSetup.Providers) http.Handler {
 	mux := http.NewServeMux()
 
 	handleFunc := func(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) {
@@ -81,42 +75,110 @@ func newHTTPHandler() http.Handler {
 
 	handleFunc("/", handleRoot)
 	handleFunc("/health", handleHealth)
+	handleFunc("/ready", server.HandleReady)
+	handleFunc("/version", handleVersion)
+	handleFunc("/fail", handleFail)
+	handleFunc("/debug/trace", handleDebugTrace)
+	handleFunc("/admin/sampling", admin.HandleSampling)
+	handleFunc("/admin/flush", admin.HandleFlush(providers))
+
+	if providers.MetricsHandler != nil {
+		mux.Handle(providers.MetricsPath, providers.MetricsHandler)
+	}
 
-	return otelhttp.NewHandler(mux, "/")
+	var handler http.Handler = mux
+	handler = middleware.SLOMetrics(serviceName, nil)(handler)
+	handler = middleware.Synthetic(middleware.SyntheticRulesFromEnv())(handler)
+	handler = middleware.BodySize()(handler)
+	handler = middleware.Goroutines(serviceName)(handler)
+	handler = middleware.CaptureHeaders()(handler)
+	handler = middleware.ClientInfo()(handler)
+	handler = middleware.ChainDepth()(handler)
+	handler = middleware.RequestID(handler)
+	handler = middleware.TraceID(handler)
+	handler = middleware.Recover(serviceName)(handler)
+
+	return otelhttp.NewHandler(handler, "/", otelSetup.ServerHandlerOptions()...)
 }
 
 func handleRoot(w http.ResponseWriter, r *http.Request) {
-	ctx, span := tracer.Start(r.Context(), "handleRoot")
+	ctx, span := otelSetup.StartServerSpan(r.Context(), tracer, r, "handleRoot")
 	defer span.End()
 
-	span.SetAttributes(
-		attribute.String("http.method", r.Method),
-		attribute.String("http.path", r.URL.Path),
-	)
+	// Requisições lentas ou com erro são priorizadas pelo tail sampling
+	start := time.Now()
+	failed := false
+	defer func() {
+		otelSetup.MarkSamplingPriority(span, time.Since(start), failed)
+	}()
 
-	log.Printf("[%s] Recebida requisição em /", serviceName)
+	logger.InfoContext(ctx, "Recebida requisição", "method", r.Method, "path", r.URL.Path)
 
-	// Chama App C
+	if faults.ShouldFail(r) {
+		failed = true
+		faults.Fail(w, r, span)
+		return
+	}
+
+	if tier := otelSetup.BaggageValue(ctx, "user.tier"); tier != "" {
+		span.SetAttributes(attrs.UserTier(tier))
+	}
+
+	// Chama App C e App D em paralelo
 	appCURL := os.Getenv("APP_C_URL")
 	if appCURL == "" {
 		appCURL = "http://localhost:8082"
 	}
+	appDURL := os.Getenv("APP_D_URL")
+	if appDURL == "" {
+		appDURL = "http://localhost:8083"
+	}
 
-	result, err := callAppC(ctx, appCURL)
-	if err != nil {
+	if otelSetup.Cancelled(ctx, span) {
+		return
+	}
+
+	var resultC, resultD fanOutResult
+	var wg sync.WaitGroup
+	wg.Go(func() { resultC = timedCall(ctx, "app-c", callAppC, appCURL) })
+	wg.Go(func() { resultD = timedCall(ctx, "app-d", callAppD, appDURL) })
+	wg.Wait()
+
+	slowest := "app-c"
+	if resultD.duration > resultC.duration {
+		slowest = "app-d"
+	}
+	span.SetAttributes(
+		attrs.FanOutAppCDuration(resultC.duration.Milliseconds()),
+		attrs.FanOutAppDDuration(resultD.duration.Milliseconds()),
+		attrs.FanOutSlowest(slowest),
+	)
+
+	if err := errors.Join(resultC.err, resultD.err); err != nil {
+		failed = true
 		span.RecordError(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		span.SetStatus(codes.Error, err.Error())
+		server.WriteErrorJSON(w, r, httpclient.ErrorStatus(err), err)
 		return
 	}
 
+	chain := httpclient.Chain(serviceName, resultC.result, resultD.result)
 	response := map[string]interface{}{
 		"service": serviceName,
-		"message": "Chamou App C com sucesso",
-		"result":  result,
+		"message": "Chamou App C e App D com sucesso",
+		"chain":   chain,
+		"slowest": slowest,
+		"results": map[string]interface{}{
+			"app-c": resultC.result,
+			"app-d": resultD.result,
+		},
 	}
 
+	span.SetAttributes(attrs.Chain(chain))
+	span.SetStatus(codes.Ok, "")
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	httpclient.EncodeJSON(ctx, w, response)
 }
 
 func callAppC(ctx context.Context, url string) (map[string]interface{}, error) {
@@ -124,43 +186,112 @@ func callAppC(ctx context.Context, url string) (map[string]interface{}, error) {
 	defer span.End()
 
 	span.SetAttributes(
-		attribute.String("app.c.url", url),
+		attrs.AppCURL(url),
 	)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url+"/", nil)
-	if err != nil {
+	var result map[string]interface{}
+	if err := httpclient.CallJSON(ctx, url+"/", &result, httpclient.WithTarget("app-c")); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	client := http.Client{
-		Transport: otelhttp.NewTransport(http.DefaultTransport),
-		Timeout:   5 * time.Second,
-	}
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+func callAppD(ctx context.Context, url string) (map[string]interface{}, error) {
+	ctx, span := tracer.Start(ctx, "callAppD")
+	defer span.End()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	span.SetAttributes(
+		attrs.AppDURL(url),
+	)
 
 	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := httpclient.CallJSON(ctx, url+"/", &result, httpclient.WithTarget("app-d")); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	span.SetAttributes(
-		attribute.Int("http.status_code", resp.StatusCode),
-	)
-
+	span.SetStatus(codes.Ok, "")
 	return result, nil
 }
 
+// fanOutResult guarda o resultado de uma das chamadas feitas em paralelo.
+type fanOutResult struct {
+	result   map[string]interface{}
+	err      error
+	duration time.Duration
+}
+
+// timedCall executa call medindo sua duração. Como ctx carrega o span de
+// handleRoot, os spans das chamadas concorrentes ficam sob o mesmo pai, que
+// também recebe os eventos de início e fim de cada chamada a target.
+func timedCall(ctx context.Context, target string, call func(context.Context, string) (map[string]interface{}, error), url string) fanOutResult {
+	span := trace.SpanFromContext(ctx)
+	opt := trace.WithAttributes(attrs.Target(target))
+
+	span.AddEvent("downstream.call.start", opt)
+	start := time.Now()
+	result, err := call(ctx, url)
+	duration := time.Since(start)
+	span.AddEvent("downstream.call.end", opt)
+
+	return fanOutResult{result: result, err: err, duration: duration}
+}
+
+// handleFail sempre falha, para demonstrar spans de erro e alertas.
+func handleFail(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "handleFail")
+	defer span.End()
+
+	faults.Fail(w, r, span)
+}
+
+// handleDebugTrace retorna o trace ID da requisição, para depuração manual.
+func handleDebugTrace(w http.ResponseWriter, r *http.Request) {
+	sc := trace.SpanContextFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(r.Context(), w, map[string]string{
+		"service":  serviceName,
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	})
+}
+
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(r.Context(), w, buildinfo.Get(serviceName))
+}
+
+// handleHealth responde OK sem verificar nada, para não gerar verificações em
+// cascata nas sondas comuns. Com ?deep=1, verifica também os serviços
+// seguintes.
 func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	if r.URL.Query().Get("deep") != "1" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
+
+	ctx, span := tracer.Start(r.Context(), "handleHealth")
+	defer span.End()
+
+	health := httpclient.DeepHealth(ctx, serviceName, map[string]string{
+		"app-c": env.String("APP_C_URL", "http://localhost:8082"),
+		"app-d": env.String("APP_D_URL", "http://localhost:8083"),
+	})
+
+	status := http.StatusOK
+	if health.Status != httpclient.HealthOK {
+		status = http.StatusServiceUnavailable
+		span.SetStatus(codes.Error, "dependência degradada")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	httpclient.EncodeJSON(ctx, w, health)
 }
@@ -2,25 +2,39 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"log"
-	"net"
+	"math/rand/v2"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"go-observability-lab/internal/admin"
+	"go-observability-lab/internal/attrs"
+	"go-observability-lab/internal/buildinfo"
+	"go-observability-lab/internal/config"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/faults"
+	"go-observability-lab/internal/httpclient"
+	"go-observability-lab/internal/middleware"
 	otelSetup "go-observability-lab/internal/otel"
+	"go-observability-lab/internal/server"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const serviceName = "app-c"
 
-var tracer = otel.Tracer(serviceName)
+var (
+	tracer = otel.Tracer(serviceName)
+	logger = otelSetup.NewLogger(serviceName)
+)
 
 func main() {
 	if err := run(); err != nil {
@@ -29,90 +43,233 @@ func main() {
 }
 
 func run() error {
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	// Depois do primeiro sinal, um segundo encerra a app imediatamente
+	context.AfterFunc(ctx, stop)
 
-	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if otlpEndpoint == "" {
-		otlpEndpoint = "localhost:4317"
-	}
-
-	otelShutdown, err := otelSetup.SetupOTelSDK(ctx, serviceName, otlpEndpoint)
+	cfg, err := config.Load(serviceName)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		err = errors.Join(err, otelShutdown(context.Background()))
-	}()
 
-	srv := &http.Server{
-		Addr:         ":8082",
-		BaseContext:  func(_ net.Listener) context.Context { return ctx },
-		ReadTimeout:  time.Second,
-		WriteTimeout: 10 * time.Second,
-		Handler:      newHTTPHandler(),
+	providers, err := otelSetup.SetupProviders(ctx, cfg)
+	if err != nil {
+		return err
 	}
 
-	srvErr := make(chan error, 1)
-	go func() {
-		log.Printf("🚀 %s iniciado na porta 8082", serviceName)
-		srvErr <- srv.ListenAndServe()
-	}()
-
-	select {
-	case err = <-srvErr:
-		return err
-	case <-ctx.Done():
-		stop()
+	addr, err := server.ListenAddr(":8082")
+	if err != nil {
+		return errors.Join(err, providers.Shutdown(context.Background()))
 	}
 
-	err = srv.Shutdown(context.Background())
-	return err
+	return server.Run(ctx, serviceName, addr, newHTTPHandler(providers), providers, cfg.ShutdownTimeout)
 }
 
-func newHTTPHandler() http.Handler {
+func newHTTPHandler(providers *otelSetup.Providers) http.Handler {
 	mux := http.NewServeMux()
 
-	handleFunc := func(pattern string, handlerFunc func(http.ResponseWriter, *http.Request)) {
-		handler := otelhttp.WithRouteTag(pattern, http.HandlerFunc(handlerFunc))
-		mux.Handle(pattern, handler)
+	handleFunc := func(pattern string, handlerFunc func(http.ResponseWriter, *http.Request), middlewares ...func(http.Handler) http.Handler) {
+		var handler http.Handler = http.HandlerFunc(handlerFunc)
+		for _, mw := range middlewares {
+			handler = mw(handler)
+		}
+		mux.Handle(pattern, otelhttp.WithRouteTag(pattern, handler))
 	}
 
-	handleFunc("/", handleRoot)
+	// Só a rota principal é limitada, para que health checks e métricas
+	// continuem respondendo durante testes de carga
+	handleFunc("/", handleRoot, middleware.RateLimit(serviceName))
 	handleFunc("/health", handleHealth)
+	handleFunc("/ready", server.HandleReady)
+	handleFunc("/version", handleVersion)
+	handleFunc("/fail", handleFail)
+	handleFunc("/debug/trace", handleDebugTrace)
+	handleFunc("/admin/sampling", admin.HandleSampling)
+	handleFunc("/admin/flush", admin.HandleFlush(providers))
+
+	if providers.MetricsHandler != nil {
+		mux.Handle(providers.MetricsPath, providers.MetricsHandler)
+	}
 
-	return otelhttp.NewHandler(mux, "/")
+	var handler http.Handler = mux
+	handler = middleware.SLOMetrics(serviceName, nil)(handler)
+	handler = middleware.Synthetic(middleware.SyntheticRulesFromEnv())(handler)
+	handler = middleware.BodySize()(handler)
+	handler = middleware.Goroutines(serviceName)(handler)
+	handler = middleware.CaptureHeaders()(handler)
+	handler = middleware.ClientInfo()(handler)
+	handler = middleware.ChainDepth()(handler)
+	handler = middleware.RequestID(handler)
+	handler = middleware.TraceID(handler)
+	handler = middleware.Recover(serviceName)(handler)
+
+	return otelhttp.NewHandler(handler, "/", otelSetup.ServerHandlerOptions()...)
 }
 
 func handleRoot(w http.ResponseWriter, r *http.Request) {
-	_, span := tracer.Start(r.Context(), "handleRoot")
+	ctx, span := otelSetup.StartServerSpan(r.Context(), tracer, r, "handleRoot")
 	defer span.End()
 
-	span.SetAttributes(
-		attribute.String("http.method", r.Method),
-		attribute.String("http.path", r.URL.Path),
-	)
+	// Requisições lentas ou com erro são priorizadas pelo tail sampling
+	start := time.Now()
+	failed := false
+	defer func() {
+		otelSetup.MarkSamplingPriority(span, time.Since(start), failed)
+	}()
+
+	logger.InfoContext(ctx, "Recebida requisição", "method", r.Method, "path", r.URL.Path)
 
-	log.Printf("[%s] Recebida requisição em /", serviceName)
+	if faults.ShouldFail(r) {
+		failed = true
+		faults.Fail(w, r, span)
+		return
+	}
+
+	// Status sorteado conforme STATUS_DISTRIBUTION, para gerar taxas de erro
+	// realistas ao longo da cadeia
+	status := faults.PickStatus()
+	if status >= 400 {
+		failed = true
+		faults.FailWithStatus(w, r, span, status)
+		return
+	}
+
+	if tier := otelSetup.BaggageValue(ctx, "user.tier"); tier != "" {
+		span.SetAttributes(attrs.UserTier(tier))
+	}
 
 	// Simula algum processamento
-	time.Sleep(100 * time.Millisecond)
+	span.AddEvent("processing.start")
+	process(ctx)
+	span.AddEvent("processing.end")
+	if otelSetup.Cancelled(ctx, span) {
+		return
+	}
+
+	queryDB(ctx, "SELECT id, status FROM orders WHERE customer_id = ?")
+	if otelSetup.Cancelled(ctx, span) {
+		return
+	}
 
+	chain := httpclient.Chain(serviceName)
 	response := map[string]interface{}{
 		"service": serviceName,
 		"message": "Resposta final do App C",
 		"status":  "success",
+		"chain":   chain,
 	}
 
 	span.SetAttributes(
-		attribute.String("response.status", "success"),
+		attrs.ResponseStatus("success"),
+		attrs.Chain(chain),
 	)
 
+	span.SetStatus(faults.SpanStatus(status))
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.WriteHeader(status)
+	httpclient.EncodeJSON(ctx, w, response)
 }
 
+// Latência simulada do processamento: APP_C_PROCESSING_MS mais um jitter
+// aleatório de até APP_C_PROCESSING_JITTER_MS.
+var (
+	processingDelay  = env.Duration("APP_C_PROCESSING_MS", 100*time.Millisecond)
+	processingJitter = env.Duration("APP_C_PROCESSING_JITTER_MS", 0)
+)
+
+func process(ctx context.Context) {
+	ctx, span := tracer.Start(ctx, "process")
+	defer span.End()
+
+	delay := processingDelay
+	if processingJitter > 0 {
+		delay += rand.N(processingJitter)
+	}
+
+	span.SetAttributes(
+		attrs.ProcessingDelay(delay.Milliseconds()),
+	)
+
+	// Interrompe a espera se o cliente desistir da requisição
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+	}
+}
+
+// dbLatency é a duração simulada de cada consulta (APP_C_DB_LATENCY_MS).
+var dbLatency = env.Duration("APP_C_DB_LATENCY_MS", 20*time.Millisecond)
+
+// queryDB simula uma consulta a um banco de dados, gerando o span de cliente
+// típico de "serviço → banco" com os atributos db.* da semconv.
+func queryDB(ctx context.Context, query string) {
+	operation, _, _ := strings.Cut(query, " ")
+	operation = strings.ToUpper(operation)
+
+	ctx, span := tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attrs.DBSystem("postgresql"),
+			attrs.DBStatement(query),
+			attrs.DBOperation(operation),
+		),
+	)
+	defer span.End()
+
+	select {
+	case <-time.After(dbLatency):
+	case <-ctx.Done():
+	}
+}
+
+// handleFail sempre falha, para demonstrar spans de erro e alertas.
+func handleFail(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "handleFail")
+	defer span.End()
+
+	faults.Fail(w, r, span)
+}
+
+// handleDebugTrace retorna o trace ID da requisição, para depuração manual.
+func handleDebugTrace(w http.ResponseWriter, r *http.Request) {
+	sc := trace.SpanContextFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(r.Context(), w, map[string]string{
+		"service":  serviceName,
+		"trace_id": sc.TraceID().String(),
+		"span_id":  sc.SpanID().String(),
+	})
+}
+
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	httpclient.EncodeJSON(r.Context(), w, buildinfo.Get(serviceName))
+}
+
+// handleHealth responde OK. Com ?deep=1, responde no formato do health check
+// profundo, que encerra a verificação transitiva da cadeia neste serviço.
 func handleHealth(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
+	if r.URL.Query().Get("deep") != "1" {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
+
+	ctx, span := tracer.Start(r.Context(), "handleHealth")
+	defer span.End()
+
+	health := httpclient.DeepHealth(ctx, serviceName, nil)
+
+	status := http.StatusOK
+	if health.Status != httpclient.HealthOK {
+		status = http.StatusServiceUnavailable
+		span.SetStatus(codes.Error, "dependência degradada")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	httpclient.EncodeJSON(ctx, w, health)
 }
@@ -0,0 +1,106 @@
+// Package admin reúne os endpoints administrativos das apps, usados durante
+// incidentes para ajustar a telemetria sem redeploy.
+//
+// Todos exigem o header "Authorization: Bearer <ADMIN_TOKEN>". Sem
+// ADMIN_TOKEN, os endpoints ficam desabilitados e respondem 404.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-observability-lab/internal/env"
+	otelSetup "go-observability-lab/internal/otel"
+	"go-observability-lab/internal/server"
+)
+
+var (
+	errDisabled     = errors.New("endpoints administrativos desabilitados (defina ADMIN_TOKEN)")
+	errUnauthorized = errors.New("token administrativo ausente ou inválido")
+)
+
+// token é o segredo exigido pelos endpoints administrativos.
+var token = env.String("ADMIN_TOKEN", "")
+
+// authorize valida o método e o token da requisição, respondendo o erro
+// adequado quando ela não é aceita.
+func authorize(w http.ResponseWriter, r *http.Request) bool {
+	if token == "" {
+		server.WriteErrorJSON(w, r, http.StatusNotFound, errDisabled)
+		return false
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		server.WriteErrorJSON(w, r, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return false
+	}
+
+	if !Authorized(r) {
+		server.WriteErrorJSON(w, r, http.StatusUnauthorized, errUnauthorized)
+		return false
+	}
+	return true
+}
+
+// Authorized informa se r traz "Authorization: Bearer <ADMIN_TOKEN>". Sem
+// ADMIN_TOKEN, nenhuma requisição é autorizada.
+func Authorized(r *http.Request) bool {
+	if token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// HandleSampling altera a razão de amostragem de traces em tempo de execução:
+// POST /admin/sampling?ratio=1.0.
+func HandleSampling(w http.ResponseWriter, r *http.Request) {
+	if !authorize(w, r) {
+		return
+	}
+
+	ratio, err := strconv.ParseFloat(r.URL.Query().Get("ratio"), 64)
+	if err != nil {
+		server.WriteErrorJSON(w, r, http.StatusBadRequest, errors.New("parâmetro ratio ausente ou inválido"))
+		return
+	}
+
+	previous := otelSetup.SamplingRatio()
+	if err := otelSetup.SetSamplingRatio(ratio); err != nil {
+		server.WriteErrorJSON(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]float64{
+		"previous_ratio": previous,
+		"ratio":          ratio,
+	})
+}
+
+// HandleFlush exporta imediatamente a telemetria pendente, sem esperar o ciclo
+// do batch processor ou do periodic reader: POST /admin/flush. Responde quanto
+// tempo o flush levou.
+func HandleFlush(telemetry server.Telemetry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorize(w, r) {
+			return
+		}
+
+		start := time.Now()
+		if err := telemetry.ForceFlush(r.Context()); err != nil {
+			server.WriteErrorJSON(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int64{
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package attrs concentra as chaves de atributos usadas nos spans das apps, para
+// que o mesmo atributo tenha sempre o mesmo nome em todos os serviços.
+//
+// Cada chave tem um construtor com o mesmo nome sem o sufixo Key, no estilo
+// do pacote semconv: attrs.Target("app-b") equivale a
+// attrs.TargetKey.String("app-b").
+package attrs
+
+import "go.opentelemetry.io/otel/attribute"
+
+// Chaves dos atributos.
+const (
+	HTTPMethodKey         = attribute.Key("http.method")
+	HTTPPathKey           = attribute.Key("http.path")
+	DeadlineRemainingKey  = attribute.Key("context.deadline_remaining_ms")
+	UserTierKey           = attribute.Key("user.tier")
+	ResponseStatusKey     = attribute.Key("response.status")
+	ProcessingDelayKey    = attribute.Key("processing.delay_ms")
+	TargetKey             = attribute.Key("target")
+	AppBURLKey            = attribute.Key("app.b.url")
+	AppCURLKey            = attribute.Key("app.c.url")
+	AppDURLKey            = attribute.Key("app.d.url")
+	FanOutAppCDurationKey = attribute.Key("fanout.app_c.duration_ms")
+	FanOutAppDDurationKey = attribute.Key("fanout.app_d.duration_ms")
+	FanOutSlowestKey      = attribute.Key("fanout.slowest")
+	BatchCountKey         = attribute.Key("batch.count")
+	BatchIndexKey         = attribute.Key("batch.index")
+	BatchFailuresKey      = attribute.Key("batch.failures")
+	MessagingSystemKey    = attribute.Key("messaging.system")
+	DBSystemKey           = attribute.Key("db.system")
+	DBStatementKey        = attribute.Key("db.statement")
+	DBOperationKey        = attribute.Key("db.operation")
+	ChainKey              = attribute.Key("chain")
+)
+
+// HTTPMethod é o método HTTP da requisição recebida.
+func HTTPMethod(v string) attribute.KeyValue { return HTTPMethodKey.String(v) }
+
+// HTTPPath é o caminho da requisição recebida.
+func HTTPPath(v string) attribute.KeyValue { return HTTPPathKey.String(v) }
+
+// DeadlineRemaining é o tempo, em ms, até o deadline do contexto.
+func DeadlineRemaining(ms int64) attribute.KeyValue { return DeadlineRemainingKey.Int64(ms) }
+
+// UserTier é o tier do usuário, propagado via baggage.
+func UserTier(v string) attribute.KeyValue { return UserTierKey.String(v) }
+
+// ResponseStatus é o resultado de negócio da resposta (ex.: "success").
+func ResponseStatus(v string) attribute.KeyValue { return ResponseStatusKey.String(v) }
+
+// ProcessingDelay é o atraso simulado de processamento, em ms.
+func ProcessingDelay(ms int64) attribute.KeyValue { return ProcessingDelayKey.Int64(ms) }
+
+// Target é o nome do serviço chamado.
+func Target(v string) attribute.KeyValue { return TargetKey.String(v) }
+
+// AppBURL é a URL usada para chamar App B.
+func AppBURL(v string) attribute.KeyValue { return AppBURLKey.String(v) }
+
+// AppCURL é a URL usada para chamar App C.
+func AppCURL(v string) attribute.KeyValue { return AppCURLKey.String(v) }
+
+// AppDURL é a URL usada para chamar App D.
+func AppDURL(v string) attribute.KeyValue { return AppDURLKey.String(v) }
+
+// FanOutAppCDuration é a duração, em ms, da chamada a App C no fan-out.
+func FanOutAppCDuration(ms int64) attribute.KeyValue { return FanOutAppCDurationKey.Int64(ms) }
+
+// FanOutAppDDuration é a duração, em ms, da chamada a App D no fan-out.
+func FanOutAppDDuration(ms int64) attribute.KeyValue { return FanOutAppDDurationKey.Int64(ms) }
+
+// FanOutSlowest é o serviço mais lento do fan-out.
+func FanOutSlowest(v string) attribute.KeyValue { return FanOutSlowestKey.String(v) }
+
+// BatchCount é a quantidade de itens do lote.
+func BatchCount(n int) attribute.KeyValue { return BatchCountKey.Int(n) }
+
+// BatchIndex é a posição do item no lote.
+func BatchIndex(i int) attribute.KeyValue { return BatchIndexKey.Int(i) }
+
+// BatchFailures é a quantidade de itens do lote que falharam.
+func BatchFailures(n int) attribute.KeyValue { return BatchFailuresKey.Int(n) }
+
+// MessagingSystem é o sistema de mensageria usado.
+func MessagingSystem(v string) attribute.KeyValue { return MessagingSystemKey.String(v) }
+
+// DBSystem é o banco de dados consultado.
+func DBSystem(v string) attribute.KeyValue { return DBSystemKey.String(v) }
+
+// DBStatement é a consulta executada.
+func DBStatement(v string) attribute.KeyValue { return DBStatementKey.String(v) }
+
+// DBOperation é a operação da consulta (ex.: "SELECT").
+func DBOperation(v string) attribute.KeyValue { return DBOperationKey.String(v) }
+
+// Chain é a cadeia de serviços que atendeu a requisição.
+func Chain(services []string) attribute.KeyValue { return ChainKey.StringSlice(services) }
@@ -0,0 +1,40 @@
+// Package buildinfo guarda as informações de build, injetadas via ldflags:
+//
+//	go build -ldflags "\
+//	  -X go-observability-lab/internal/buildinfo.Version=1.2.3 \
+//	  -X go-observability-lab/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X go-observability-lab/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//	  ./cmd/app-a
+package buildinfo
+
+import "go-observability-lab/internal/env"
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info é a resposta do endpoint /version.
+type Info struct {
+	Service   string `json:"service"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// ServiceVersion retorna a versão do serviço. SERVICE_VERSION tem precedência
+// sobre o valor injetado no build.
+func ServiceVersion() string {
+	return env.String("SERVICE_VERSION", Version)
+}
+
+// Get retorna as informações de build do serviço.
+func Get(service string) Info {
+	return Info{
+		Service:   service,
+		Version:   ServiceVersion(),
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+}
@@ -0,0 +1,172 @@
+// Package config carrega a configuração de telemetria das apps a partir de um
+// arquivo YAML ou JSON opcional (CONFIG_FILE), sobrescrito pelas variáveis de
+// ambiente equivalentes.
+//
+// Exemplo de arquivo:
+//
+//	service_name: app-a
+//	endpoint: otel-collector:4317
+//	protocol: grpc
+//	sampling_ratio: 0.5
+//	shutdown_timeout: 10s
+//	dial_timeout: 5s
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-observability-lab/internal/env"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne as opções de telemetria compartilhadas pelas apps.
+type Config struct {
+	// ServiceName é o service.name reportado (OTEL_SERVICE_NAME).
+	ServiceName string
+	// Endpoint é o endereço OTLP compartilhado pelos sinais
+	// (OTEL_EXPORTER_OTLP_ENDPOINT).
+	Endpoint string
+	// Protocol é o protocolo OTLP; apenas "grpc" é suportado
+	// (OTEL_EXPORTER_OTLP_PROTOCOL).
+	Protocol string
+	// SamplingRatio é a fração de traces amostrados na raiz, entre 0 e 1
+	// (OTEL_TRACES_SAMPLER_ARG).
+	SamplingRatio float64
+	// ShutdownTimeout limita o flush e o encerramento (SHUTDOWN_TIMEOUT).
+	ShutdownTimeout time.Duration
+	// DialTimeout limita cada tentativa de conexão ao collector
+	// (OTEL_EXPORTER_OTLP_DIAL_TIMEOUT).
+	DialTimeout time.Duration
+}
+
+// fileConfig é o formato do arquivo. As durações são strings do Go ("5s").
+type fileConfig struct {
+	ServiceName     string   `json:"service_name" yaml:"service_name"`
+	Endpoint        string   `json:"endpoint" yaml:"endpoint"`
+	Protocol        string   `json:"protocol" yaml:"protocol"`
+	SamplingRatio   *float64 `json:"sampling_ratio" yaml:"sampling_ratio"`
+	ShutdownTimeout string   `json:"shutdown_timeout" yaml:"shutdown_timeout"`
+	DialTimeout     string   `json:"dial_timeout" yaml:"dial_timeout"`
+}
+
+// Default retorna a configuração usada quando nada é informado.
+func Default(serviceName string) Config {
+	return Config{
+		ServiceName:     serviceName,
+		Endpoint:        "localhost:4317",
+		Protocol:        "grpc",
+		SamplingRatio:   1,
+		ShutdownTimeout: 10 * time.Second,
+		DialTimeout:     5 * time.Second,
+	}
+}
+
+// Load parte de Default(serviceName), aplica o arquivo indicado em CONFIG_FILE
+// (se existir) e, por fim, as variáveis de ambiente.
+func Load(serviceName string) (Config, error) {
+	cfg := Default(serviceName)
+
+	if path := env.String("CONFIG_FILE", ""); path != "" {
+		err := cfg.loadFile(path)
+		switch {
+		case err == nil:
+			log.Printf("📄 Configuração carregada de %s", path)
+		case errors.Is(err, fs.ErrNotExist):
+			log.Printf("⚠️ CONFIG_FILE %s não encontrado, usando valores padrão", path)
+		default:
+			return Config{}, err
+		}
+	}
+
+	cfg.applyEnv()
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadFile decodifica o arquivo conforme a extensão (.json, .yaml ou .yml) e
+// sobrescreve os campos definidos nele.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var fc fileConfig
+	switch ext := filepath.Ext(path); ext {
+	case ".json":
+		err = json.Unmarshal(data, &fc)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &fc)
+	default:
+		return fmt.Errorf("CONFIG_FILE com extensão não suportada: %q (use .json, .yaml ou .yml)", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	if fc.ServiceName != "" {
+		c.ServiceName = fc.ServiceName
+	}
+	if fc.Endpoint != "" {
+		c.Endpoint = fc.Endpoint
+	}
+	if fc.Protocol != "" {
+		c.Protocol = fc.Protocol
+	}
+	if fc.SamplingRatio != nil {
+		c.SamplingRatio = *fc.SamplingRatio
+	}
+	if c.ShutdownTimeout, err = parseDuration("shutdown_timeout", fc.ShutdownTimeout, c.ShutdownTimeout); err != nil {
+		return err
+	}
+	if c.DialTimeout, err = parseDuration("dial_timeout", fc.DialTimeout, c.DialTimeout); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyEnv sobrescreve os campos com as variáveis de ambiente definidas.
+func (c *Config) applyEnv() {
+	if name := env.String("OTEL_SERVICE_NAME", ""); name != "" && name != c.ServiceName {
+		log.Printf("ℹ️ OTEL_SERVICE_NAME definido: usando %q em vez de %q", name, c.ServiceName)
+		c.ServiceName = name
+	}
+	c.Endpoint = env.String("OTEL_EXPORTER_OTLP_ENDPOINT", c.Endpoint)
+	c.Protocol = env.String("OTEL_EXPORTER_OTLP_PROTOCOL", c.Protocol)
+	c.SamplingRatio = env.Float("OTEL_TRACES_SAMPLER_ARG", c.SamplingRatio)
+	c.ShutdownTimeout = env.Duration("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	c.DialTimeout = env.Duration("OTEL_EXPORTER_OTLP_DIAL_TIMEOUT", c.DialTimeout)
+}
+
+func (c *Config) validate() error {
+	if c.Protocol != "grpc" {
+		return fmt.Errorf("protocolo OTLP não suportado: %q (use grpc)", c.Protocol)
+	}
+	if c.SamplingRatio < 0 || c.SamplingRatio > 1 {
+		return fmt.Errorf("sampling_ratio deve estar entre 0 e 1: %v", c.SamplingRatio)
+	}
+	return nil
+}
+
+// parseDuration interpreta o campo key do arquivo, mantendo def quando vazio.
+func parseDuration(key, v string, def time.Duration) (time.Duration, error) {
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s inválido no arquivo de configuração: %w", key, err)
+	}
+	return d, nil
+}
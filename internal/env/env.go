@@ -0,0 +1,101 @@
+// Package env concentra a leitura de variáveis de ambiente com valores padrão.
+//
+// Valores inválidos nunca derrubam a aplicação: um aviso é registrado e o
+// valor padrão é usado.
+package env
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// String retorna o valor da variável ou def quando ela não está definida.
+func String(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
+
+// Bool interpreta a variável com strconv.ParseBool.
+func Bool(key string, def bool) bool {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		warnInvalid(key, v, def)
+		return def
+	}
+	return b
+}
+
+// Int interpreta a variável como um inteiro decimal.
+func Int(key string, def int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		warnInvalid(key, v, def)
+		return def
+	}
+	return n
+}
+
+// Float interpreta a variável como um número de ponto flutuante.
+func Float(key string, def float64) float64 {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		warnInvalid(key, v, def)
+		return def
+	}
+	return f
+}
+
+// Duration aceita tanto o formato do Go ("500ms", "2s") quanto um inteiro
+// puro, interpretado como milissegundos, que é a convenção da especificação
+// do OpenTelemetry para variáveis como OTEL_BSP_SCHEDULE_DELAY.
+func Duration(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	if ms, err := strconv.Atoi(v); err == nil {
+		return time.Duration(ms) * time.Millisecond
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		warnInvalid(key, v, def)
+		return def
+	}
+	return d
+}
+
+// List separa a variável por vírgulas, descartando itens vazios. Retorna def
+// quando a variável não está definida ou não contém nenhum item.
+func List(key string, def []string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return def
+	}
+	return items
+}
+
+func warnInvalid(key, value string, def any) {
+	log.Printf("⚠️ Valor inválido para %s (%q), usando padrão: %v", key, value, def)
+}
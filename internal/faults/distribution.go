@@ -0,0 +1,101 @@
+package faults
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/server"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// statusWeight é uma entrada de STATUS_DISTRIBUTION.
+type statusWeight struct {
+	code   int
+	weight int
+}
+
+// distribution é a distribuição de status codes lida de STATUS_DISTRIBUTION,
+// no formato "200:90,500:5,429:5" (status:peso). Vazia, todas as respostas são
+// 200. Só são aceitos códigos 2xx, 4xx e 5xx que levam o corpo JSON da
+// resposta: 1xx, 3xx, 204 e 205 são ignorados.
+var distribution = parseDistribution(env.List("STATUS_DISTRIBUTION", nil))
+
+func parseDistribution(entries []string) []statusWeight {
+	var ws []statusWeight
+	for _, entry := range entries {
+		code, weight, err := parseStatusWeight(entry)
+		if err != nil {
+			log.Printf("⚠️ Entrada inválida em STATUS_DISTRIBUTION ignorada (%q): %v", entry, err)
+			continue
+		}
+		ws = append(ws, statusWeight{code: code, weight: weight})
+	}
+	return ws
+}
+
+func parseStatusWeight(entry string) (code, weight int, err error) {
+	codeStr, weightStr, ok := strings.Cut(entry, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("use status:peso")
+	}
+	code, err = strconv.Atoi(strings.TrimSpace(codeStr))
+	if err != nil || code < 100 || code > 599 {
+		return 0, 0, fmt.Errorf("status code inválido: %q", codeStr)
+	}
+	if code < 200 || code >= 300 && code < 400 || code == http.StatusNoContent || code == http.StatusResetContent {
+		return 0, 0, fmt.Errorf("status code sem suporte (use 2xx com corpo, 4xx ou 5xx): %d", code)
+	}
+	weight, err = strconv.Atoi(strings.TrimSpace(weightStr))
+	if err != nil || weight < 0 {
+		return 0, 0, fmt.Errorf("peso inválido: %q", weightStr)
+	}
+	return code, weight, nil
+}
+
+// PickStatus sorteia um status code conforme STATUS_DISTRIBUTION, ou retorna
+// 200 quando a variável não está definida.
+func PickStatus() int {
+	total := 0
+	for _, w := range distribution {
+		total += w.weight
+	}
+	if total == 0 {
+		return http.StatusOK
+	}
+
+	n := rand.IntN(total)
+	for _, w := range distribution {
+		if n < w.weight {
+			return w.code
+		}
+		n -= w.weight
+	}
+	return http.StatusOK
+}
+
+// SpanStatus retorna o status do span de servidor para uma resposta com code:
+// erro a partir de 400, ok nos demais casos.
+func SpanStatus(code int) (codes.Code, string) {
+	if code >= 400 {
+		return codes.Error, http.StatusText(code)
+	}
+	return codes.Ok, ""
+}
+
+// FailWithStatus registra uma falha injetada com o status code informado no
+// span e responde com ele.
+func FailWithStatus(w http.ResponseWriter, r *http.Request, span trace.Span, code int) {
+	err := fmt.Errorf("%w: status %d", ErrInjected, code)
+	span.RecordError(err)
+	span.SetAttributes(attribute.Int("fault.status_code", code))
+	span.SetStatus(codes.Error, err.Error())
+	server.WriteErrorJSON(w, r, code, err)
+}
@@ -0,0 +1,37 @@
+// Package faults injeta falhas sintéticas para validar traces de erro, status
+// codes e a propagação de erros entre os serviços.
+package faults
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/server"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInjected é o erro registrado nas falhas injetadas.
+var ErrInjected = errors.New("falha injetada para teste")
+
+// rate é a fração (de 0 a 1) das requisições que devem falhar.
+var rate = env.Float("ERROR_INJECTION_RATE", 0)
+
+// ShouldFail informa se a requisição deve falhar: sempre que ?fail=1 estiver
+// presente ou, caso contrário, com probabilidade ERROR_INJECTION_RATE.
+func ShouldFail(r *http.Request) bool {
+	if r.URL.Query().Get("fail") == "1" {
+		return true
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+// Fail registra ErrInjected no span e responde 500.
+func Fail(w http.ResponseWriter, r *http.Request, span trace.Span) {
+	span.RecordError(ErrInjected)
+	span.SetStatus(codes.Error, ErrInjected.Error())
+	server.WriteErrorJSON(w, r, http.StatusInternalServerError, ErrInjected)
+}
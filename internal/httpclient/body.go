@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrBodyReadTimeout indica que o cliente não enviou o corpo da requisição
+// dentro de BODY_READ_TIMEOUT.
+var ErrBodyReadTimeout = errors.New("tempo esgotado lendo o corpo da requisição")
+
+// bodyReadTimeout limita a leitura do corpo das requisições recebidas.
+var bodyReadTimeout = env.Duration("BODY_READ_TIMEOUT", 5*time.Second)
+
+// ReadBody lê o corpo de r, para rotas que recebem POST, dentro do span
+// readBody. Um corpo maior que maxBytes é cortado pelo http.MaxBytesReader e
+// retorna *http.MaxBytesError. A leitura tem um read deadline na conexão de
+// BODY_READ_TIMEOUT (padrão 5s), ou o deadline de ctx se vier antes; um cliente
+// que não termina de enviar a tempo recebe ErrBodyReadTimeout. Assim um cliente
+// lento (slowloris) não prende a goroutine do handler. ErrorStatus converte os
+// dois erros em 413 e 408.
+//
+// Os bytes lidos são registrados em http.request.body.size no span de ctx.
+func ReadBody(ctx context.Context, w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, error) {
+	parent := trace.SpanFromContext(ctx)
+	_, span := tracer.Start(ctx, "readBody", trace.WithAttributes(
+		attribute.Int64("request.max_bytes", maxBytes),
+	))
+	defer span.End()
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	deadline := time.Now().Add(bodyReadTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	// Sem suporte a deadline (ex.: ResponseRecorder), lê sem limite de tempo
+	rc := http.NewResponseController(w)
+	hasDeadline := rc.SetReadDeadline(deadline) == nil
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+	parent.SetAttributes(attribute.Int64("http.request.body.size", int64(len(body))))
+
+	var maxErr *http.MaxBytesError
+	var netErr net.Error
+	switch {
+	case err == nil:
+		// Remove o deadline só depois de uma leitura completa: o servidor
+		// continua lendo a conexão em segundo plano e cancelaria o contexto da
+		// requisição ao estourá-lo. Em caso de erro ele é mantido, para que o
+		// servidor não fique preso descartando o resto do corpo.
+		if hasDeadline {
+			rc.SetReadDeadline(time.Time{})
+		}
+		return body, nil
+	case errors.As(err, &maxErr):
+		span.AddEvent("request.too_large")
+	case errors.Is(err, os.ErrDeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		err = fmt.Errorf("%w: %w", ErrBodyReadTimeout, err)
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return nil, err
+}
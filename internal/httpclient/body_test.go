@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newBodyServer sobe um servidor cuja rota lê o corpo com ReadBody e responde
+// com o status de ErrorStatus em caso de erro.
+func newBodyServer(t *testing.T, maxBytes int64) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ReadBody(r.Context(), w, r, maxBytes); err != nil {
+			http.Error(w, err.Error(), ErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestReadBodyStalledClient(t *testing.T) {
+	const timeout = 100 * time.Millisecond
+	defer func(prev time.Duration) { bodyReadTimeout = prev }(bodyReadTimeout)
+	bodyReadTimeout = timeout
+
+	srv := newBodyServer(t, 1<<10)
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Anuncia 100 bytes e envia só 5, sem fechar a conexão
+	start := time.Now()
+	fmt.Fprint(conn, "POST / HTTP/1.1\r\nHost: lab\r\nContent-Length: 100\r\n\r\nhello")
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("lendo resposta: %v", err)
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start)
+
+	if resp.StatusCode != http.StatusRequestTimeout {
+		t.Errorf("status = %d, esperado %d", resp.StatusCode, http.StatusRequestTimeout)
+	}
+	if elapsed > timeout+time.Second {
+		t.Errorf("ReadBody levou %v, esperado perto de %v", elapsed, timeout)
+	}
+}
+
+func TestReadBodyTooLarge(t *testing.T) {
+	srv := newBodyServer(t, 10)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "dentro do limite", body: strings.Repeat("a", 10), wantStatus: http.StatusNoContent},
+		{name: "acima do limite", body: strings.Repeat("a", 11), wantStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, esperado %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
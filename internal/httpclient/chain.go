@@ -0,0 +1,17 @@
+package httpclient
+
+// Chain monta a cadeia de serviços que atendeu a requisição: service seguido
+// das cadeias, no campo "chain", das respostas dos serviços chamados, na
+// ordem recebida. Respostas sem o campo contribuem com nada.
+func Chain(service string, results ...map[string]interface{}) []string {
+	chain := []string{service}
+	for _, result := range results {
+		next, _ := result["chain"].([]interface{})
+		for _, name := range next {
+			if name, ok := name.(string); ok {
+				chain = append(chain, name)
+			}
+		}
+	}
+	return chain
+}
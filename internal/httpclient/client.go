@@ -0,0 +1,285 @@
+// Package httpclient concentra as chamadas HTTP entre os serviços do lab, com
+// instrumentação, timeout e retry padronizados.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-observability-lab/internal/attrs"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/middleware"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("go-observability-lab/internal/httpclient")
+
+// Falhas transitórias (erros de rede e 5xx) são repetidas com backoff
+// exponencial, até APP_CALL_MAX_RETRIES novas tentativas.
+var (
+	maxRetries     = env.Int("APP_CALL_MAX_RETRIES", 3)
+	initialBackoff = 100 * time.Millisecond
+	maxBackoff     = 2 * time.Second
+)
+
+// timeout limita cada tentativa de chamada.
+var timeout = env.Duration("DOWNSTREAM_TIMEOUT", 5*time.Second)
+
+// maxResponseBytes limita o corpo lido de cada resposta, para que um serviço
+// com problema não esgote a memória de quem o chama.
+var maxResponseBytes = int64(env.Int("MAX_RESPONSE_BYTES", 1<<20))
+
+var client = &http.Client{
+	Transport: otelhttp.NewTransport(newTransport()),
+	Timeout:   timeout,
+}
+
+// newTransport cria o transport compartilhado pelas chamadas, com o pool de
+// conexões ajustável por DOWNSTREAM_MAX_IDLE_CONNS_PER_HOST (padrão 10) e
+// DOWNSTREAM_IDLE_CONN_TIMEOUT (padrão 90s). Com o padrão do net/http, de
+// duas conexões ociosas por host, chamadas concorrentes abrem conexões novas.
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = env.Int("DOWNSTREAM_MAX_IDLE_CONNS_PER_HOST", 10)
+	transport.IdleConnTimeout = env.Duration("DOWNSTREAM_IDLE_CONN_TIMEOUT", 90*time.Second)
+	return transport
+}
+
+// StatusError representa uma resposta de erro do serviço chamado. Body guarda
+// o corpo da resposta (até MAX_RESPONSE_BYTES), para quem sabe interpretá-lo.
+type StatusError struct {
+	URL         string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s respondeu com status %d", e.URL, e.StatusCode)
+}
+
+// ErrorStatus retorna o status com que um handler deve responder quando uma
+// chamada feita por CallJSON ou a leitura de ReadBody falha: o 508 de um loop
+// na cadeia é repassado, para chegar ao cliente original; ErrThrottled vira
+// 503, para que a contrapressão apareça para quem chama; um corpo grande
+// demais vira 413 e um cliente lento, 408; os demais erros viram 500.
+func ErrorStatus(err error) int {
+	var statusErr *StatusError
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusLoopDetected:
+		return http.StatusLoopDetected
+	case errors.Is(err, ErrThrottled):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrBodyReadTimeout):
+		return http.StatusRequestTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// ErrResponseTooLarge indica que o corpo da resposta ultrapassou
+// MAX_RESPONSE_BYTES.
+var ErrResponseTooLarge = errors.New("resposta maior que o limite permitido")
+
+// Option personaliza uma chamada feita por CallJSON.
+type Option func(*callConfig)
+
+type callConfig struct {
+	target     string
+	maxRetries int
+}
+
+// WithTarget nomeia o serviço chamado (por exemplo, "app-b") nas métricas.
+// Sem ela, o host da URL é usado.
+func WithTarget(name string) Option {
+	return func(c *callConfig) {
+		c.target = name
+	}
+}
+
+// WithMaxRetries substitui APP_CALL_MAX_RETRIES nesta chamada; 0 desativa as
+// novas tentativas.
+func WithMaxRetries(n int) Option {
+	return func(c *callConfig) {
+		c.maxRetries = n
+	}
+}
+
+// CallJSON faz um GET em url e decodifica o corpo JSON da resposta em out.
+// Cada tentativa é registrada em um span filho com a URL e o status code.
+//
+// ctx deve ser derivado do contexto da requisição recebida: se o chamador
+// cancelar ou desconectar, a chamada em andamento (e as tentativas seguintes)
+// é cancelada também, propagando o cancelamento pela cadeia.
+//
+// Com DOWNSTREAM_MAX_CONCURRENCY, o número de tentativas simultâneas do
+// processo é limitado; saturado, CallJSON espera uma vaga ou, com
+// DOWNSTREAM_CONCURRENCY_MODE=fail, retorna ErrThrottled.
+func CallJSON(ctx context.Context, url string, out any, opts ...Option) (err error) {
+	cfg := callConfig{target: hostOf(url), maxRetries: maxRetries}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	defer trackActive(ctx, cfg.target)()
+
+	start := time.Now()
+	defer func() {
+		if errors.Is(err, context.DeadlineExceeded) {
+			recordTimeout(ctx, cfg.target)
+		}
+		recordCall(ctx, cfg.target, start, err)
+	}()
+
+	backoff := initialBackoff
+	for attempt := 0; ; attempt++ {
+		release, err := limiter.acquire(ctx, cfg.target)
+		if err != nil {
+			return err
+		}
+		retryable, err := callJSONAttempt(ctx, url, out, attempt)
+		release()
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= cfg.maxRetries {
+			return err
+		}
+
+		// Não agenda uma nova tentativa que ultrapassaria o deadline do contexto
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			recordCancellation(ctx)
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// callJSONAttempt faz uma única tentativa de chamada. O retorno retryable
+// indica se a falha é transitória.
+func callJSONAttempt(ctx context.Context, url string, out any, attempt int) (retryable bool, err error) {
+	ctx, span := tracer.Start(ctx, "CallJSON", trace.WithAttributes(
+		attribute.String("http.url", url),
+		attribute.Int("retry.attempt", attempt),
+		attribute.Int64("downstream.timeout_ms", timeout.Milliseconds()),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	// Registra se a conexão veio do pool, para diagnosticar keep-alives que
+	// não estão funcionando ao longo da cadeia
+	traceCtx := httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			span.SetAttributes(attribute.Bool("http.connection.reused", info.Reused))
+		},
+	})
+
+	req, err := http.NewRequestWithContext(traceCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	if id := middleware.RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(middleware.RequestIDHeader, id)
+	}
+	req.Header.Set(middleware.ChainDepthHeader, strconv.Itoa(middleware.ChainDepthFromContext(ctx)+1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// Cancelamento ou deadline do chamador não devem gerar novas tentativas
+		if ctx.Err() != nil {
+			recordCancellation(ctx)
+			return false, err
+		}
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	span.SetAttributes(
+		attribute.Int("http.status_code", resp.StatusCode),
+		attribute.String("http.status_class", statusClass(resp.StatusCode)),
+	)
+
+	// Qualquer resposta fora de 2xx é um erro, para não mascarar falhas do
+	// serviço chamado; só 5xx vale uma nova tentativa, exceto o 508 de
+	// middleware.ChainDepth, que se repetiria igual
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode >= 500 && resp.StatusCode != http.StatusLoopDetected
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+		return retryable, &StatusError{
+			URL:         url,
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        body,
+		}
+	}
+
+	// Lê um byte além do limite para detectar respostas grandes demais
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return true, err
+	}
+	if int64(len(body)) > maxResponseBytes {
+		span.AddEvent("response.too_large", trace.WithAttributes(
+			attribute.Int64("response.max_bytes", maxResponseBytes),
+		))
+		return false, fmt.Errorf("%w: %s excedeu %d bytes", ErrResponseTooLarge, url, maxResponseBytes)
+	}
+
+	return false, DecodeJSON(ctx, body, out)
+}
+
+// recordCancellation registra no span atual que o cancelamento do contexto
+// de origem interrompeu a chamada.
+func recordCancellation(ctx context.Context) {
+	trace.SpanFromContext(ctx).AddEvent("downstream.cancelled", trace.WithAttributes(
+		attribute.String("cause", context.Cause(ctx).Error()),
+	))
+}
+
+// recordTimeout registra no span atual que a chamada a target falhou por
+// estouro de deadline, deixando visíveis as cascatas de timeout.
+func recordTimeout(ctx context.Context, target string) {
+	trace.SpanFromContext(ctx).AddEvent("downstream.timeout", trace.WithAttributes(
+		attrs.Target(target),
+	))
+}
+
+// statusClass retorna a classe do status code, como "2xx" ou "5xx".
+func statusClass(code int) string {
+	return fmt.Sprintf("%dxx", code/100)
+}
+
+// hostOf retorna o host de rawURL, ou a própria string se ela não puder ser
+// interpretada.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"go-observability-lab/internal/otel/testutil"
+)
+
+var providers *testutil.TestProviders
+
+func TestMain(m *testing.M) {
+	providers = testutil.NewTestProviders()
+	code := m.Run()
+	providers.Shutdown(context.Background())
+	os.Exit(code)
+}
+
+func TestCallJSONCancelled(t *testing.T) {
+	providers.Reset()
+
+	var attempts atomic.Int32
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		started <- struct{}{}
+		// Segura a resposta até o cliente desistir
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	var out map[string]any
+	err := CallJSON(ctx, srv.URL, &out, WithMaxRetries(3))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, esperado context.Canceled", err)
+	}
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("tentativas = %d, esperado 1", n)
+	}
+
+	var found bool
+	for _, span := range providers.Spans(context.Background()) {
+		if span.Name != "CallJSON" {
+			continue
+		}
+		for _, event := range span.Events {
+			if event.Name == "downstream.cancelled" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("evento downstream.cancelled não registrado no span CallJSON")
+	}
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"sync"
+
+	"go-observability-lab/internal/attrs"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Health é a resposta do health check profundo (/health?deep=1).
+type Health struct {
+	Service      string            `json:"service"`
+	Status       string            `json:"status"`
+	Error        string            `json:"error,omitempty"`
+	Dependencies map[string]Health `json:"dependencies,omitempty"`
+}
+
+// Status possíveis de Health.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+)
+
+// DeepHealth verifica em paralelo o /health?deep=1 de cada dependência (nome
+// para URL base), o que torna a verificação transitiva ao longo da cadeia. O
+// serviço fica degradado se alguma dependência não estiver ok.
+func DeepHealth(ctx context.Context, service string, deps map[string]string) Health {
+	health := Health{Service: service, Status: HealthOK}
+	if len(deps) == 0 {
+		return health
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	health.Dependencies = make(map[string]Health, len(deps))
+	for name, url := range deps {
+		wg.Go(func() {
+			dep := checkHealth(ctx, name, url)
+
+			mu.Lock()
+			defer mu.Unlock()
+			health.Dependencies[name] = dep
+			if dep.Status != HealthOK {
+				health.Status = HealthDegraded
+			}
+		})
+	}
+	wg.Wait()
+
+	return health
+}
+
+// checkHealth consulta o health check profundo de target em um span próprio,
+// sem novas tentativas, para que uma dependência fora do ar responda rápido.
+func checkHealth(ctx context.Context, target, url string) Health {
+	ctx, span := tracer.Start(ctx, "checkHealth", trace.WithAttributes(
+		attrs.Target(target),
+	))
+	defer span.End()
+
+	var dep Health
+	if err := CallJSON(ctx, url+"/health?deep=1", &dep, WithTarget(target), WithMaxRetries(0)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if !healthFromError(ctx, err, &dep) {
+			return Health{Service: target, Status: HealthDegraded, Error: err.Error()}
+		}
+	}
+
+	if dep.Status != HealthOK {
+		span.SetStatus(codes.Error, "dependência degradada")
+	}
+	return dep
+}
+
+// healthFromError decodifica em dep o corpo JSON de uma resposta de erro, como
+// o 503 de uma dependência degradada, para não perder o detalhe das
+// dependências dela. Retorna false se o corpo não tem JSON válido.
+func healthFromError(ctx context.Context, err error, dep *Health) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	if mediaType, _, _ := mime.ParseMediaType(statusErr.ContentType); mediaType != "application/json" {
+		return false
+	}
+	if DecodeJSON(ctx, statusErr.Body, dep) != nil {
+		return false
+	}
+
+	// Uma resposta de erro nunca conta como ok
+	if dep.Status == HealthOK || dep.Status == "" {
+		dep.Status = HealthDegraded
+	}
+	return true
+}
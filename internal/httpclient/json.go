@@ -0,0 +1,47 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EncodeJSON serializa v em w dentro do span encodeResponse, registrando o
+// tamanho do payload para tornar o custo de serialização visível no trace.
+func EncodeJSON(ctx context.Context, w io.Writer, v any) error {
+	_, span := tracer.Start(ctx, "encodeResponse")
+	defer span.End()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	// Mantém o "\n" final que o json.Encoder escrevia
+	data = append(data, '\n')
+
+	span.SetAttributes(attribute.Int("payload.bytes", len(data)))
+
+	if _, err := w.Write(data); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// DecodeJSON decodifica data em out dentro do span decodeResponse.
+func DecodeJSON(ctx context.Context, data []byte, out any) error {
+	_, span := tracer.Start(ctx, "decodeResponse", trace.WithAttributes(
+		attribute.Int("payload.bytes", len(data)),
+	))
+	defer span.End()
+
+	if err := json.Unmarshal(data, out); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
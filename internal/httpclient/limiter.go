@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-observability-lab/internal/attrs"
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrThrottled indica que a chamada foi recusada porque o limite de chamadas
+// simultâneas estava atingido, com DOWNSTREAM_CONCURRENCY_MODE=fail.
+var ErrThrottled = errors.New("limite de chamadas simultâneas aos serviços seguintes atingido")
+
+// limiter limita as tentativas de chamada simultâneas do processo.
+var limiter = newConcurrencyLimiter(
+	env.Int("DOWNSTREAM_MAX_CONCURRENCY", 0),
+	env.String("DOWNSTREAM_CONCURRENCY_MODE", "block"),
+)
+
+// concurrencyLimiter é um semáforo que modela a contrapressão de um pool de
+// conexões: com todas as vagas ocupadas, uma nova tentativa espera uma vaga
+// ("block") ou falha na hora com ErrThrottled ("fail").
+type concurrencyLimiter struct {
+	slots    chan struct{}
+	failFast bool
+}
+
+// newConcurrencyLimiter retorna nil, sem limite, quando maxConcurrent não é
+// positivo.
+func newConcurrencyLimiter(maxConcurrent int, mode string) *concurrencyLimiter {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	if mode != "block" && mode != "fail" {
+		log.Printf("⚠️ DOWNSTREAM_CONCURRENCY_MODE inválido (%q), usando block", mode)
+		mode = "block"
+	}
+	return &concurrencyLimiter{slots: make(chan struct{}, maxConcurrent), failFast: mode == "fail"}
+}
+
+// acquire ocupa uma vaga para uma tentativa de chamada a target e retorna a
+// função que a libera. Quando não há vaga, registra o evento
+// downstream.throttled no span de ctx, marcado no início da espera e, se
+// esperou, com a duração em downstream.wait_ms; cada tentativa tem o seu
+// evento.
+func (l *concurrencyLimiter) acquire(ctx context.Context, target string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	release := func() { <-l.slots }
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	span := trace.SpanFromContext(ctx)
+	kvs := []attribute.KeyValue{
+		attrs.Target(target),
+		attribute.Int("downstream.max_concurrency", cap(l.slots)),
+	}
+	if l.failFast {
+		span.AddEvent("downstream.throttled", trace.WithAttributes(kvs...))
+		return nil, fmt.Errorf("%w: %s", ErrThrottled, target)
+	}
+
+	start := time.Now()
+	defer func() {
+		span.AddEvent("downstream.throttled",
+			trace.WithTimestamp(start),
+			trace.WithAttributes(append(kvs, attribute.Int64("downstream.wait_ms", time.Since(start).Milliseconds()))...),
+		)
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"context"
+	"time"
+
+	"go-observability-lab/internal/attrs"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Métricas do lado cliente, para identificar qual salto da cadeia está
+// degradando independentemente da visão do servidor. Os instrumentos do meter
+// global passam a exportar assim que o MeterProvider é instalado.
+var (
+	meter = otel.Meter("go-observability-lab/internal/httpclient")
+
+	requestsTotal = instrument(meter.Int64Counter("downstream.requests.total",
+		metric.WithDescription("Chamadas aos serviços seguintes por alvo e resultado"),
+		metric.WithUnit("{request}")))
+
+	requestDuration = instrument(meter.Float64Histogram("downstream.request.duration",
+		metric.WithDescription("Duração das chamadas aos serviços seguintes, incluindo retries"),
+		metric.WithUnit("s")))
+
+	activeRequests = instrument(meter.Int64UpDownCounter("downstream.active_requests",
+		metric.WithDescription("Chamadas aos serviços seguintes em andamento"),
+		metric.WithUnit("{request}")))
+)
+
+// instrument repassa para otel.Handle o erro de criação de um instrumento, em
+// vez de descartá-lo.
+func instrument[T any](inst T, err error) T {
+	if err != nil {
+		otel.Handle(err)
+	}
+	return inst
+}
+
+// trackActive marca uma chamada a target como em andamento e retorna a função
+// que a desmarca.
+func trackActive(ctx context.Context, target string) func() {
+	opt := metric.WithAttributes(attrs.Target(target))
+	activeRequests.Add(ctx, 1, opt)
+	return func() {
+		activeRequests.Add(ctx, -1, opt)
+	}
+}
+
+// recordCall registra o resultado de uma chamada completa a target.
+func recordCall(ctx context.Context, target string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+
+	opt := metric.WithAttributes(
+		attrs.Target(target),
+		attribute.String("outcome", outcome),
+	)
+	requestsTotal.Add(ctx, 1, opt)
+	requestDuration.Record(ctx, time.Since(start).Seconds(), opt)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BodySize registra http.request.body.size e http.response.body.size no span
+// do servidor, contando os bytes efetivamente lidos e escritos. É opt-in via
+// HTTP_CAPTURE_BODY_SIZE=true para evitar o overhead quando não é necessário.
+func BodySize() func(http.Handler) http.Handler {
+	enabled := env.Bool("HTTP_CAPTURE_BODY_SIZE", false)
+
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body *countingReader
+			if r.Body != nil && r.Body != http.NoBody {
+				body = &countingReader{ReadCloser: r.Body}
+				r.Body = body
+			}
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			// Se o handler não leu o corpo, o Content-Length é a melhor estimativa
+			requestSize := max(r.ContentLength, 0)
+			if body != nil && body.read > 0 {
+				requestSize = body.read
+			}
+
+			trace.SpanFromContext(r.Context()).SetAttributes(
+				attribute.Int64("http.request.body.size", requestSize),
+				attribute.Int64("http.response.body.size", rec.written),
+			)
+		})
+	}
+}
+
+// countingReader conta os bytes lidos do corpo da requisição.
+type countingReader struct {
+	io.ReadCloser
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/server"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChainDepthHeader conta os saltos já feitos pela requisição na cadeia de
+// serviços. O httpclient o repassa incrementado a cada chamada.
+const ChainDepthHeader = "X-Chain-Depth"
+
+// ErrChainDepthExceeded indica que a requisição passou de MAX_CHAIN_DEPTH
+// saltos, provavelmente por um loop de chamadas.
+var ErrChainDepthExceeded = errors.New("profundidade máxima da cadeia de chamadas excedida")
+
+type chainDepthKey struct{}
+
+// ChainDepthFromContext retorna quantos saltos a requisição do contexto já
+// fez, ou 0 se ela não veio de outro serviço.
+func ChainDepthFromContext(ctx context.Context) int {
+	depth, _ := ctx.Value(chainDepthKey{}).(int)
+	return depth
+}
+
+// ChainDepth protege a cadeia contra loops, como APP_B_URL apontando de volta
+// para App A: requisições com X-Chain-Depth acima de MAX_CHAIN_DEPTH (padrão 10)
+// recebem 508 e o evento chain.depth.exceeded no span do servidor. As demais
+// seguem com a profundidade no contexto, para o httpclient repassá-la.
+func ChainDepth() func(http.Handler) http.Handler {
+	maxDepth := env.Int("MAX_CHAIN_DEPTH", 10)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Um valor inválido conta como início da cadeia
+			depth, _ := strconv.Atoi(r.Header.Get(ChainDepthHeader))
+			depth = max(depth, 0)
+
+			if depth > maxDepth {
+				trace.SpanFromContext(r.Context()).AddEvent("chain.depth.exceeded", trace.WithAttributes(
+					attribute.Int("chain.depth", depth),
+					attribute.Int("chain.max_depth", maxDepth),
+				))
+				server.WriteErrorJSON(w, r, http.StatusLoopDetected,
+					fmt.Errorf("%w: %d saltos (máximo %d)", ErrChainDepthExceeded, depth, maxDepth))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), chainDepthKey{}, depth)))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClientInfo registra no span do servidor o IP real do cliente
+// (client.address) e o User-Agent (user_agent.original), para auditoria.
+//
+// X-Forwarded-For só é considerado com TRUSTED_PROXY_COUNT > 0, o número de
+// proxies confiáveis na frente da app. Cada um acrescenta um endereço ao fim da
+// cadeia, então o cliente é o endereço nessa posição a partir do fim; entradas
+// mais à esquerda podem ter sido forjadas pelo próprio cliente.
+func ClientInfo() func(http.Handler) http.Handler {
+	trustedProxies := env.Int("TRUSTED_PROXY_COUNT", 0)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attrs := []attribute.KeyValue{
+				attribute.String("client.address", clientAddress(r, trustedProxies)),
+			}
+			if ua := r.UserAgent(); ua != "" {
+				attrs = append(attrs, attribute.String("user_agent.original", ua))
+			}
+			trace.SpanFromContext(r.Context()).SetAttributes(attrs...)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientAddress retorna o IP do cliente considerando trustedProxies entradas
+// confiáveis no fim de X-Forwarded-For.
+func clientAddress(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(header, ",") {
+				if hop = strings.TrimSpace(hop); hop != "" {
+					hops = append(hops, hop)
+				}
+			}
+		}
+		if len(hops) > 0 {
+			return hops[max(len(hops)-trustedProxies, 0)]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
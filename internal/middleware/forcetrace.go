@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	otelSetup "go-observability-lab/internal/otel"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// ForceTrace decide na entrada se a requisição deve ser rastreada à força. Como
+// ForceTraceBaggageKey ignora a razão de amostragem, o header baggage recebido
+// só é aceito de chamadores em que trusted confia (por exemplo, com o token
+// administrativo): nesse caso, com ForceTraceBaggageKey=1, a chave é colocada
+// no baggage do contexto. Dos demais, a chave é removida do header antes da
+// extração do otelhttp, para que um cliente externo não force 100% de
+// amostragem na cadeia.
+//
+// Deve envolver o otelhttp.NewHandler do serviço de entrada, para que o sampler
+// já veja a chave ao criar o span do servidor (mesmo com OTEL_PROPAGATORS sem
+// baggage) e os serviços seguintes a recebam pelo httpclient.
+func ForceTrace(trusted func(*http.Request) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// O propagator junta todos os headers baggage, e descarta por inteiro
+			// um header inválido
+			bag, err := baggage.Parse(strings.Join(r.Header.Values("baggage"), ","))
+			if err != nil || bag.Member(otelSetup.ForceTraceBaggageKey).Key() == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !trusted(r) {
+				r = r.Clone(r.Context())
+				bag = bag.DeleteMember(otelSetup.ForceTraceBaggageKey)
+				if bag.Len() == 0 {
+					r.Header.Del("baggage")
+				} else {
+					r.Header.Set("baggage", bag.String())
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if bag.Member(otelSetup.ForceTraceBaggageKey).Value() != "1" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, err := otelSetup.WithBaggageValue(r.Context(), otelSetup.ForceTraceBaggageKey, "1")
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
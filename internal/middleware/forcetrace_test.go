@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	otelSetup "go-observability-lab/internal/otel"
+)
+
+func TestForceTrace(t *testing.T) {
+	tests := []struct {
+		name       string
+		baggage    []string
+		trusted    bool
+		wantHeader string
+		wantCtxKey string
+	}{
+		{
+			name:       "chamador confiável",
+			baggage:    []string{"trace.force=1"},
+			trusted:    true,
+			wantHeader: "trace.force=1",
+			wantCtxKey: "1",
+		},
+		{
+			name:       "chamador externo mantém os demais membros",
+			baggage:    []string{"user.tier=premium,trace.force=1"},
+			wantHeader: "user.tier=premium",
+		},
+		{
+			name:       "chamador externo em um segundo header",
+			baggage:    []string{"user.tier=premium", "trace.force=1"},
+			wantHeader: "user.tier=premium",
+		},
+		{
+			name:    "só trace.force",
+			baggage: []string{"trace.force=1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			handler := ForceTrace(func(*http.Request) bool { return tt.trusted })(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r }),
+			)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for _, v := range tt.baggage {
+				r.Header.Add("baggage", v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if header := strings.Join(got.Header.Values("baggage"), ","); header != tt.wantHeader {
+				t.Errorf("baggage = %q, esperado %q", header, tt.wantHeader)
+			}
+
+			if v := otelSetup.BaggageValue(got.Context(), otelSetup.ForceTraceBaggageKey); v != tt.wantCtxKey {
+				t.Errorf("%s no contexto = %q, esperado %q", otelSetup.ForceTraceBaggageKey, v, tt.wantCtxKey)
+			}
+		})
+	}
+}
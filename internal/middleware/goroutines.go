@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Goroutines registra o gauge app.goroutines ao fim de cada requisição e, se
+// o número de goroutines cresceu mais que GOROUTINE_GROWTH_THRESHOLD (padrão
+// 10) durante ela, marca o span do servidor com goroutines.growth. Com
+// requisições concorrentes o crescimento é ruidoso, mas um valor que só sobe
+// indica goroutines vazando, por exemplo, no fan-out. É opt-in via
+// TRACK_GOROUTINES=true.
+func Goroutines(serviceName string) func(http.Handler) http.Handler {
+	enabled := env.Bool("TRACK_GOROUTINES", false)
+	threshold := env.Int("GOROUTINE_GROWTH_THRESHOLD", 10)
+
+	gauge, err := otel.Meter(serviceName).Int64Gauge("app.goroutines",
+		metric.WithDescription("Goroutines em execução ao fim de cada requisição"),
+		metric.WithUnit("{goroutine}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			before := runtime.NumGoroutine()
+			next.ServeHTTP(w, r)
+			after := runtime.NumGoroutine()
+
+			gauge.Record(r.Context(), int64(after))
+			if growth := after - before; growth > threshold {
+				trace.SpanFromContext(r.Context()).SetAttributes(
+					attribute.Int("goroutines.growth", growth),
+				)
+			}
+		})
+	}
+}
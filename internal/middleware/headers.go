@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CaptureHeaders copia os headers listados em CAPTURE_HEADERS (separados por
+// vírgula) para o span do servidor como http.request.header.<nome>, seguindo
+// a convenção semântica. Sem a variável, o middleware não tem efeito.
+func CaptureHeaders() func(http.Handler) http.Handler {
+	type capturedHeader struct {
+		name string
+		key  attribute.Key
+	}
+
+	var headers []capturedHeader
+	for _, name := range env.List("CAPTURE_HEADERS", nil) {
+		headers = append(headers, capturedHeader{
+			name: http.CanonicalHeaderKey(name),
+			key:  attribute.Key("http.request.header." + sanitizeHeaderName(name)),
+		})
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(headers) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span := trace.SpanFromContext(r.Context())
+			for _, h := range headers {
+				if values := r.Header.Values(h.name); len(values) > 0 {
+					span.SetAttributes(h.key.StringSlice(values))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// sanitizeHeaderName normaliza o nome do header para uso em chave de
+// atributo: minúsculas, mantendo apenas letras, dígitos, "-" e "_".
+func sanitizeHeaderName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(name))
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/server"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrRateLimited é o erro da resposta 429 de RateLimit.
+var ErrRateLimited = errors.New("limite de requisições excedido")
+
+// RateLimit limita as requisições com um token bucket de RATE_LIMIT_RPS
+// requisições por segundo e rajadas de até RATE_LIMIT_BURST (padrão: o próprio
+// RPS, no mínimo 1). Requisições acima do limite recebem 429, o evento
+// rate_limited no span do servidor e incrementam
+// http.server.rate_limited.total. É opt-in: sem RATE_LIMIT_RPS, não limita.
+//
+// Cada chamada cria um bucket próprio, então o middleware pode ser aplicado só
+// nas rotas que precisam de proteção, cada uma com seu limite.
+func RateLimit(serviceName string) func(http.Handler) http.Handler {
+	rps := env.Float("RATE_LIMIT_RPS", 0)
+	burst := env.Int("RATE_LIMIT_BURST", max(int(rps), 1))
+
+	limited, err := otel.Meter(serviceName).Int64Counter("http.server.rate_limited.total",
+		metric.WithDescription("Requisições rejeitadas pelo rate limiter"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		if rps <= 0 {
+			return next
+		}
+
+		bucket := newTokenBucket(rps, burst)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !bucket.allow() {
+				trace.SpanFromContext(r.Context()).AddEvent("rate_limited", trace.WithAttributes(
+					attribute.Float64("rate_limit.rps", rps),
+				))
+				limited.Add(r.Context(), 1, metric.WithAttributes(
+					attribute.String("http.route", r.Pattern),
+				))
+				server.WriteErrorJSON(w, r, http.StatusTooManyRequests, ErrRateLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tokenBucket repõe rate tokens por segundo, acumulando até burst.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// allow consome um token, se houver.
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import "net/http"
+
+// responseRecorder guarda o status code e a quantidade de bytes escritos pelo
+// handler, inclusive em escritas em streaming, e se a resposta já começou.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	r.status = code
+	// Respostas 1xx são informativas e não iniciam a resposta final
+	if code >= 200 {
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.written += int64(n)
+	return n, err
+}
+
+// Unwrap permite que http.ResponseController alcance o writer original
+// (Flush, deadlines etc.).
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"go-observability-lab/internal/server"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Recover captura panics dos handlers, registra-os no span do servidor como
+// erro (com o stack trace), incrementa panics.total e responde 500, se o
+// handler ainda não tiver começado a resposta. Deve ser o middleware mais
+// externo dentro do otelhttp, para cobrir todos os demais.
+func Recover(serviceName string) func(http.Handler) http.Handler {
+	panics, err := otel.Meter(serviceName).Int64Counter("panics.total",
+		metric.WithDescription("Panics recuperados nos handlers HTTP"),
+		metric.WithUnit("{panic}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := newResponseRecorder(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// http.ErrAbortHandler é a forma padrão de abortar a resposta
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				err := fmt.Errorf("panic: %v", v)
+				log.Printf("❌ Panic em %s %s: %v", r.Method, r.URL.Path, v)
+
+				// Executado durante o panic, o stack trace inclui a origem dele
+				span := trace.SpanFromContext(r.Context())
+				span.RecordError(err, trace.WithStackTrace(true))
+				span.SetStatus(codes.Error, err.Error())
+				panics.Add(r.Context(), 1)
+
+				// Com a resposta já começada, um 500 só geraria um WriteHeader
+				// supérfluo e JSON colado ao corpo parcial
+				if rec.wroteHeader {
+					return
+				}
+				// O valor do panic fica só no span e no log, não na resposta
+				server.WriteErrorJSON(w, r, http.StatusInternalServerError,
+					errors.New(http.StatusText(http.StatusInternalServerError)))
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "panic antes da resposta",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("falha") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `"code":"internal_server_error"`,
+		},
+		{
+			name: "panic com a resposta começada",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte("parcial"))
+				panic("falha")
+			},
+			wantStatus: http.StatusAccepted,
+			wantBody:   "parcial",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Recover("test")(tt.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, esperado %d", w.Code, tt.wantStatus)
+			}
+			body := w.Body.String()
+			if !strings.Contains(body, tt.wantBody) {
+				t.Errorf("corpo = %q, esperado conter %q", body, tt.wantBody)
+			}
+			if tt.wantStatus != http.StatusInternalServerError && body != tt.wantBody {
+				t.Errorf("corpo = %q, esperado só %q", body, tt.wantBody)
+			}
+		})
+	}
+}
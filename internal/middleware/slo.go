@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// SLOMetrics registra as métricas usadas no cálculo de SLO apenas para
+// tráfego real: requisições marcadas por Synthetic são ignoradas.
+//
+// Requisições mais lentas que o limite da rota incrementam
+// http.server.slo.violations. routeThresholds define limites por rota
+// (r.Pattern); as demais usam SLO_THRESHOLD_MS (padrão 500ms).
+//
+// Deve ser o middleware mais interno, logo antes do mux, para que a rota
+// (r.Pattern) já esteja resolvida quando a requisição termina.
+func SLOMetrics(serviceName string, routeThresholds map[string]time.Duration) func(http.Handler) http.Handler {
+	meter := otel.Meter(serviceName)
+	defaultThreshold := env.Duration("SLO_THRESHOLD_MS", 500*time.Millisecond)
+
+	requests, err := meter.Int64Counter("http.server.slo.requests",
+		metric.WithDescription("Requisições de usuários reais consideradas no SLO"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	duration, err := meter.Float64Histogram("http.server.slo.request.duration",
+		metric.WithDescription("Duração das requisições de usuários reais"),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	violations, err := meter.Int64Counter("http.server.slo.violations",
+		metric.WithDescription("Requisições de usuários reais que excederam o limite de latência da rota"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsSynthetic(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			attrs := metric.WithAttributes(
+				attribute.String("http.route", r.Pattern),
+				attribute.Int("http.response.status_code", rec.status),
+			)
+			elapsed := time.Since(start)
+			requests.Add(r.Context(), 1, attrs)
+			duration.Record(r.Context(), elapsed.Seconds(), attrs)
+
+			threshold, ok := routeThresholds[r.Pattern]
+			if !ok {
+				threshold = defaultThreshold
+			}
+			if elapsed > threshold {
+				violations.Add(r.Context(), 1, metric.WithAttributes(
+					attribute.String("http.route", r.Pattern),
+				))
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package middleware reúne os middlewares HTTP compartilhados pelas apps.
+//
+// Todos eles devem ser aplicados por dentro do otelhttp.NewHandler, para que o
+// span do servidor já esteja disponível no contexto da requisição.
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SyntheticAttr marca spans originados por tráfego de monitoramento.
+var SyntheticAttr = attribute.Bool("synthetic", true)
+
+// SyntheticRules define como identificar tráfego sintético (uptime checks,
+// probes de monitoramento etc.).
+type SyntheticRules struct {
+	// Header cujo valor "true" marca a requisição como sintética.
+	Header string
+	// UserAgents contém trechos de User-Agent comparados sem diferenciar
+	// maiúsculas de minúsculas.
+	UserAgents []string
+}
+
+// SyntheticRulesFromEnv lê as regras de SYNTHETIC_HEADER e
+// SYNTHETIC_USER_AGENTS (separados por vírgula).
+func SyntheticRulesFromEnv() SyntheticRules {
+	return SyntheticRules{
+		Header:     env.String("SYNTHETIC_HEADER", "X-Synthetic"),
+		UserAgents: env.List("SYNTHETIC_USER_AGENTS", []string{"UptimeRobot", "Pingdom", "StatusCake"}),
+	}
+}
+
+// Match informa se a requisição corresponde a alguma das regras.
+func (s SyntheticRules) Match(r *http.Request) bool {
+	if s.Header != "" && strings.EqualFold(strings.TrimSpace(r.Header.Get(s.Header)), "true") {
+		return true
+	}
+	ua := strings.ToLower(r.UserAgent())
+	if ua == "" {
+		return false
+	}
+	for _, fragment := range s.UserAgents {
+		if strings.Contains(ua, strings.ToLower(fragment)) {
+			return true
+		}
+	}
+	return false
+}
+
+type syntheticKey struct{}
+
+// IsSynthetic informa se a requisição associada ao contexto foi marcada como
+// tráfego sintético.
+func IsSynthetic(ctx context.Context) bool {
+	synthetic, _ := ctx.Value(syntheticKey{}).(bool)
+	return synthetic
+}
+
+// Synthetic marca as requisições sintéticas com synthetic=true no span do
+// servidor e no contexto, para que as métricas de SLO possam ignorá-las.
+func Synthetic(rules SyntheticRules) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !rules.Match(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			trace.SpanFromContext(ctx).SetAttributes(SyntheticAttr)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, syntheticKey{}, true)))
+		})
+	}
+}
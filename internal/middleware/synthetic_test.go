@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSyntheticRulesMatch(t *testing.T) {
+	rules := SyntheticRules{
+		Header:     "X-Synthetic",
+		UserAgents: []string{"UptimeRobot", "Pingdom"},
+	}
+
+	tests := []struct {
+		name      string
+		header    string
+		userAgent string
+		want      bool
+	}{
+		{name: "header true", header: "true", userAgent: "curl/8.0", want: true},
+		{name: "header sem diferenciar maiúsculas", header: " TRUE ", want: true},
+		{name: "trecho de user-agent", userAgent: "Mozilla/5.0 (compatible; uptimerobot/2.0)", want: true},
+		{name: "header falso", header: "false", userAgent: "curl/8.0", want: false},
+		{name: "sem correspondência", userAgent: "Mozilla/5.0", want: false},
+		{name: "sem user-agent", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Synthetic", tt.header)
+			}
+			r.Header.Set("User-Agent", tt.userAgent)
+
+			if got := rules.Match(r); got != tt.want {
+				t.Errorf("Match = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSLOMetricsSkipsSynthetic(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer meterProvider.Shutdown(context.Background())
+	otel.SetMeterProvider(meterProvider)
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler = SLOMetrics("test", nil)(handler)
+	handler = Synthetic(SyntheticRules{Header: "X-Synthetic"})(handler)
+
+	synthetic := httptest.NewRequest(http.MethodGet, "/", nil)
+	synthetic.Header.Set("X-Synthetic", "true")
+	handler.ServeHTTP(httptest.NewRecorder(), synthetic)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "http.server.slo.requests" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				total += dp.Value
+			}
+		}
+	}
+	if total != 1 {
+		t.Errorf("http.server.slo.requests = %d, esperado 1 (só a requisição real)", total)
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader é o header de resposta com o trace ID da requisição.
+const TraceIDHeader = "X-Trace-Id"
+
+// TraceID devolve em X-Trace-Id o trace ID do span do servidor, para que o ID
+// possa ser colado direto na UI de traces. Deve rodar dentro do otelhttp.
+func TraceID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			w.Header().Set(TraceIDHeader, sc.TraceID().String())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
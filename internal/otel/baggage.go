@@ -0,0 +1,27 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// WithBaggageValue retorna uma cópia de ctx com key=val no baggage. O valor é
+// propagado para os serviços chamados pelo propagator de baggage.
+func WithBaggageValue(ctx context.Context, key, val string) (context.Context, error) {
+	member, err := baggage.NewMemberRaw(key, val)
+	if err != nil {
+		return ctx, err
+	}
+
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx, err
+	}
+	return baggage.ContextWithBaggage(ctx, bag), nil
+}
+
+// BaggageValue retorna o valor de key no baggage de ctx, ou "" se ausente.
+func BaggageValue(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
@@ -0,0 +1,26 @@
+package otel
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// emitCanary exporta um único span startup.canary e força o flush, para que
+// um endpoint errado ou um problema de TLS apareça já na subida da app em vez
+// de todos os spans serem descartados silenciosamente. O resultado só é
+// registrado no log: a app sobe de qualquer forma.
+func emitCanary(ctx context.Context, tracerProvider *trace.TracerProvider) {
+	ctx, cancel := context.WithTimeout(ctx, otlpTimeout())
+	defer cancel()
+
+	_, span := tracerProvider.Tracer("go-observability-lab/internal/otel").Start(ctx, "startup.canary")
+	span.End()
+
+	if err := tracerProvider.ForceFlush(ctx); err != nil {
+		log.Printf("❌ Span canário não foi exportado: %v", err)
+		return
+	}
+	log.Printf("✅ Span canário exportado com sucesso")
+}
@@ -0,0 +1,22 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Cancelled informa se ctx já foi cancelado (por exemplo, porque o cliente
+// desconectou) e, nesse caso, registra o evento request.cancelled em span. Os
+// handlers o consultam nos pontos em que ainda há trabalho pela frente, para
+// abortar cedo em vez de desperdiçá-lo.
+func Cancelled(ctx context.Context, span trace.Span) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	span.AddEvent("request.cancelled", trace.WithAttributes(
+		attribute.String("cause", context.Cause(ctx).Error()),
+	))
+	return true
+}
@@ -0,0 +1,22 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InjectMap serializa o contexto de trace (e o baggage) de ctx em um mapa de
+// headers, como os de uma mensagem de fila, usando o propagador configurado.
+func InjectMap(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// ExtractMap devolve ctx com o contexto de trace lido de headers, para que o
+// consumidor de uma mensagem continue o trace de quem a publicou.
+func ExtractMap(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
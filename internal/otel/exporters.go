@@ -0,0 +1,85 @@
+package otel
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	otellog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// connectionLogExporter registra no log a primeira exportação bem-sucedida.
+// Como a conexão gRPC é estabelecida em segundo plano, esse é o primeiro
+// momento em que sabemos que o collector está de fato acessível.
+type connectionLogExporter struct {
+	trace.SpanExporter
+	endpoint string
+	once     sync.Once
+}
+
+func (e *connectionLogExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err == nil {
+		e.once.Do(func() {
+			log.Printf("✅ Conectado ao collector OTLP em %s", e.endpoint)
+		})
+	}
+	return err
+}
+
+// exportFailures conta as exportações que falharam, por sinal, permitindo
+// alertar quando a própria telemetria está quebrada.
+var exportFailures = func() metric.Int64Counter {
+	counter, err := otel.Meter("go-observability-lab/internal/otel").Int64Counter("otel.export.failures.total",
+		metric.WithDescription("Exportações de telemetria que retornaram erro"),
+		metric.WithUnit("{failure}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+	return counter
+}()
+
+func recordExportFailure(ctx context.Context, signal string, err error) {
+	if err != nil {
+		exportFailures.Add(ctx, 1, metric.WithAttributes(attribute.String("signal", signal)))
+	}
+}
+
+// failureCountingSpanExporter conta as falhas de exportação de spans.
+type failureCountingSpanExporter struct {
+	trace.SpanExporter
+}
+
+func (e failureCountingSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	recordExportFailure(ctx, "traces", err)
+	return err
+}
+
+// failureCountingMetricExporter conta as falhas de exportação de métricas.
+type failureCountingMetricExporter struct {
+	sdkmetric.Exporter
+}
+
+func (e failureCountingMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	recordExportFailure(ctx, "metrics", err)
+	return err
+}
+
+// failureCountingLogExporter conta as falhas de exportação de logs.
+type failureCountingLogExporter struct {
+	otellog.Exporter
+}
+
+func (e failureCountingLogExporter) Export(ctx context.Context, records []otellog.Record) error {
+	err := e.Exporter.Export(ctx, records)
+	recordExportFailure(ctx, "logs", err)
+	return err
+}
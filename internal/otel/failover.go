@@ -0,0 +1,70 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// failoverSpanExporter alterna entre o collector principal e o de backup
+// (OTEL_EXPORTER_OTLP_ENDPOINT_FALLBACK). Depois de
+// OTEL_EXPORTER_OTLP_FAILOVER_THRESHOLD (padrão 3) falhas seguidas no destino
+// ativo, passa a exportar para o outro; o lote que completou as falhas é
+// reenviado ao novo destino, para não ser perdido. Se o backup também falhar
+// seguidamente, volta ao principal, o que cobre o fim da manutenção.
+type failoverSpanExporter struct {
+	endpoints [2]string
+	exporters [2]trace.SpanExporter
+	threshold int
+
+	mu       sync.Mutex
+	active   int
+	failures int
+}
+
+func newFailoverSpanExporter(primaryEndpoint string, primary trace.SpanExporter, fallbackEndpoint string, fallback trace.SpanExporter) *failoverSpanExporter {
+	return &failoverSpanExporter{
+		endpoints: [2]string{primaryEndpoint, fallbackEndpoint},
+		exporters: [2]trace.SpanExporter{primary, fallback},
+		threshold: max(env.Int("OTEL_EXPORTER_OTLP_FAILOVER_THRESHOLD", 3), 1),
+	}
+}
+
+func (e *failoverSpanExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	// O batch span processor exporta um lote por vez, mas ForceFlush pode
+	// concorrer com ele; o lock mantém a contagem de falhas consistente
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err := e.exporters[e.active].ExportSpans(ctx, spans)
+	if err == nil {
+		e.failures = 0
+		return nil
+	}
+
+	e.failures++
+	if e.failures < e.threshold {
+		return err
+	}
+
+	from := e.active
+	e.active = 1 - e.active
+	e.failures = 0
+	log.Printf("⚠️ %d falhas seguidas exportando traces para %s, alternando para %s: %v",
+		e.threshold, e.endpoints[from], e.endpoints[e.active], err)
+
+	if retryErr := e.exporters[e.active].ExportSpans(ctx, spans); retryErr != nil {
+		e.failures++
+		return errors.Join(err, retryErr)
+	}
+	return nil
+}
+
+func (e *failoverSpanExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.exporters[0].Shutdown(ctx), e.exporters[1].Shutdown(ctx))
+}
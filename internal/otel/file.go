@@ -0,0 +1,140 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newFileSpanExporter cria o exporter de OTEL_TRACES_EXPORTER=file, que grava
+// um span JSON por linha em OTEL_TRACES_FILE_PATH (padrão traces.jsonl), para
+// depurar sem collector. Quando o arquivo passa de OTEL_TRACES_FILE_MAX_BYTES
+// (padrão 100MB), ele é renomeado para <path>.1, substituindo o anterior, e um
+// arquivo novo é iniciado; assim o disco usado fica limitado a cerca de duas
+// vezes o limite.
+func newFileSpanExporter() (trace.SpanExporter, error) {
+	path := env.String("OTEL_TRACES_FILE_PATH", "traces.jsonl")
+	maxBytes := int64(env.Int("OTEL_TRACES_FILE_MAX_BYTES", 100<<20))
+
+	file, err := openRotatingFile(path, maxBytes)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir o arquivo de traces %s: %w", path, err)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+	log.Printf("📝 Exportando traces para o arquivo %s", path)
+	return fileSpanExporter{SpanExporter: exporter, file: file}, nil
+}
+
+// fileSpanExporter fecha o arquivo ao encerrar o exporter.
+type fileSpanExporter struct {
+	trace.SpanExporter
+	file *rotatingFile
+}
+
+func (e fileSpanExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.file.Close())
+}
+
+// rotatingFile é um io.Writer sobre path que troca de arquivo ao passar de
+// maxBytes. A troca acontece entre escritas, e o stdouttrace escreve um span
+// por chamada, então nenhuma linha é dividida entre os arquivos.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func openRotatingFile(path string, maxBytes int64) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxBytes: maxBytes}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Join(err, file.Close())
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Uma rotação anterior não conseguiu reabrir o arquivo
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+		log.Printf("✅ Arquivo de traces %s reaberto", f.path)
+	}
+
+	if f.maxBytes > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxBytes {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate troca de arquivo. Se o arquivo novo não puder ser aberto, f.file fica
+// nil e a abertura é tentada de novo na próxima escrita.
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		log.Printf("⚠️ Erro ao fechar o arquivo de traces %s: %v", f.path, err)
+		return err
+	}
+
+	renamed := true
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		// Sem a rotação, continua no mesmo arquivo em vez de perder spans
+		// e só tenta de novo depois de mais maxBytes
+		log.Printf("⚠️ Erro ao rotacionar o arquivo de traces %s: %v", f.path, err)
+		renamed = false
+	}
+	if err := f.open(); err != nil {
+		log.Printf("⚠️ Erro ao reabrir o arquivo de traces %s, tentando de novo na próxima escrita: %v", f.path, err)
+		return err
+	}
+	if !renamed {
+		f.size = 0
+	}
+	return nil
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
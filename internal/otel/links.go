@@ -0,0 +1,21 @@
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartLinkedSpan inicia um span com links para os span contexts informados,
+// ignorando os inválidos. Links correlacionam spans que não têm relação de
+// pai e filho, como os itens de um lote processados em traces separados.
+func StartLinkedSpan(ctx context.Context, tracer trace.Tracer, name string, linked []trace.SpanContext, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(linked))
+	for _, sc := range linked {
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+
+	return tracer.Start(ctx, name, append(opts, trace.WithLinks(links...))...)
+}
@@ -0,0 +1,52 @@
+package otel
+
+import (
+	"context"
+	"log"
+	"log/slog"
+
+	"go-observability-lab/internal/env"
+
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// logLevel lê LOG_LEVEL ("debug", "info", o padrão, "warn" ou "error").
+func logLevel() slog.Level {
+	var level slog.Level
+	v := env.String("LOG_LEVEL", "info")
+	if err := level.UnmarshalText([]byte(v)); err != nil {
+		log.Printf("⚠️ Valor inválido para LOG_LEVEL (%q), usando padrão: info", v)
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// severityOf converte um nível do slog na severidade equivalente do
+// OpenTelemetry, com o mesmo mapeamento da bridge otelslog.
+func severityOf(level slog.Level) otellog.Severity {
+	const offset = slog.Level(otellog.SeverityDebug) - slog.LevelDebug
+	return otellog.Severity(level + offset)
+}
+
+// minSeverityProcessor descarta os registros abaixo de min antes que cheguem
+// ao batch processor, reduzindo o volume exportado.
+type minSeverityProcessor struct {
+	sdklog.Processor
+	min otellog.Severity
+}
+
+func newMinSeverityProcessor(next sdklog.Processor, level slog.Level) minSeverityProcessor {
+	return minSeverityProcessor{Processor: next, min: severityOf(level)}
+}
+
+func (p minSeverityProcessor) Enabled(ctx context.Context, param sdklog.EnabledParameters) bool {
+	return param.Severity >= p.min && p.Processor.Enabled(ctx, param)
+}
+
+func (p minSeverityProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if record.Severity() < p.min {
+		return nil
+	}
+	return p.Processor.OnEmit(ctx, record)
+}
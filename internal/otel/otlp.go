@@ -0,0 +1,221 @@
+package otel
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// defaultOTLPPort é a porta padrão do OTLP/gRPC.
+const defaultOTLPPort = "4317"
+
+// otlpEndpoint é um endpoint OTLP já interpretado por parseEndpoint.
+type otlpEndpoint struct {
+	hostPort string
+	insecure bool
+}
+
+// parseEndpoint aceita tanto host:porta quanto URLs como http://host:4317. O
+// esquema define a conexão: "http" sem TLS e "https" com TLS; sem esquema, a
+// conexão é sem TLS, como antes. A porta padrão é a 4317.
+func parseEndpoint(raw string) (otlpEndpoint, error) {
+	if !strings.Contains(raw, "://") {
+		host, port, err := net.SplitHostPort(raw)
+		if err != nil {
+			// Sem porta: usa a padrão
+			host, port = raw, defaultOTLPPort
+		}
+		if host == "" || strings.ContainsAny(host, "/?#") {
+			return otlpEndpoint{}, fmt.Errorf("endpoint OTLP inválido: %q (use host:porta ou http(s)://host:porta)", raw)
+		}
+		return otlpEndpoint{hostPort: net.JoinHostPort(host, port), insecure: true}, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return otlpEndpoint{}, fmt.Errorf("endpoint OTLP inválido: %q: %w", raw, err)
+	}
+	if u.Hostname() == "" {
+		return otlpEndpoint{}, fmt.Errorf("endpoint OTLP inválido: %q não tem host", raw)
+	}
+
+	var insecure bool
+	switch u.Scheme {
+	case "http":
+		insecure = true
+	case "https":
+		insecure = false
+	default:
+		return otlpEndpoint{}, fmt.Errorf("endpoint OTLP inválido: esquema %q não suportado em %q (use http ou https)", u.Scheme, raw)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultOTLPPort
+	}
+	return otlpEndpoint{hostPort: net.JoinHostPort(u.Hostname(), port), insecure: insecure}, nil
+}
+
+// traceExporterOptions reúne as opções do exporter OTLP/gRPC de traces.
+//
+// A conexão gRPC não bloqueia: a app sobe mesmo com o collector fora do ar e
+// passa a exportar quando ele ficar acessível. dialTimeout limita cada
+// tentativa de conexão, refeita com backoff exponencial.
+func traceExporterOptions(endpoint otlpEndpoint, dialTimeout time.Duration) []otlptracegrpc.Option {
+	opts := []otlptracegrpc.Option{
+		otlptracegrpc.WithEndpoint(endpoint.hostPort),
+		otlptracegrpc.WithReconnectionPeriod(dialTimeout),
+		otlptracegrpc.WithTimeout(otlpTimeout()),
+		otlptracegrpc.WithDialOption(grpc.WithKeepaliveParams(keepaliveParams())),
+		otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig(retrySettings())),
+	}
+	if endpoint.insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	if compressor := otlpCompression(); compressor != "" {
+		opts = append(opts, otlptracegrpc.WithCompressor(compressor))
+	}
+	return opts
+}
+
+// metricExporterOptions reúne as opções do exporter OTLP/gRPC de métricas.
+func metricExporterOptions(endpoint otlpEndpoint) []otlpmetricgrpc.Option {
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(endpoint.hostPort),
+		otlpmetricgrpc.WithTimeout(otlpTimeout()),
+		otlpmetricgrpc.WithTemporalitySelector(temporalitySelector()),
+		otlpmetricgrpc.WithDialOption(grpc.WithKeepaliveParams(keepaliveParams())),
+		otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(retrySettings())),
+	}
+	if endpoint.insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+	if compressor := otlpCompression(); compressor != "" {
+		opts = append(opts, otlpmetricgrpc.WithCompressor(compressor))
+	}
+	return opts
+}
+
+// logExporterOptions reúne as opções do exporter OTLP/gRPC de logs.
+func logExporterOptions(endpoint otlpEndpoint) []otlploggrpc.Option {
+	opts := []otlploggrpc.Option{
+		otlploggrpc.WithEndpoint(endpoint.hostPort),
+		otlploggrpc.WithTimeout(otlpTimeout()),
+		otlploggrpc.WithDialOption(grpc.WithKeepaliveParams(keepaliveParams())),
+		otlploggrpc.WithRetry(otlploggrpc.RetryConfig(retrySettings())),
+	}
+	if endpoint.insecure {
+		opts = append(opts, otlploggrpc.WithInsecure())
+	}
+	if compressor := otlpCompression(); compressor != "" {
+		opts = append(opts, otlploggrpc.WithCompressor(compressor))
+	}
+	return opts
+}
+
+// otlpTimeout lê OTEL_EXPORTER_OTLP_TIMEOUT, o tempo máximo de cada exportação,
+// com o padrão de 10s do SDK. Um collector lento não segura o batch processor
+// por mais que isso.
+func otlpTimeout() time.Duration {
+	return env.Duration("OTEL_EXPORTER_OTLP_TIMEOUT", 10*time.Second)
+}
+
+// retryConfig tem os mesmos campos do RetryConfig de cada exporter OTLP, que
+// são tipos distintos, e pode ser convertido para qualquer um deles.
+type retryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// retrySettings lê como os exporters repetem exportações que falharam, com os
+// padrões do SDK:
+//
+//   - OTEL_EXPORTER_OTLP_RETRY_ENABLED: false desliga as novas tentativas
+//     (padrão true);
+//   - OTEL_EXPORTER_OTLP_RETRY_INITIAL_INTERVAL: espera antes da primeira nova
+//     tentativa (padrão 5s), que cresce exponencialmente;
+//   - OTEL_EXPORTER_OTLP_RETRY_MAX_INTERVAL: espera máxima entre tentativas
+//     (padrão 30s);
+//   - OTEL_EXPORTER_OTLP_RETRY_MAX_ELAPSED_TIME: tempo total antes de desistir
+//     do lote (padrão 1m).
+//
+// Enquanto um lote é repetido, os novos spans se acumulam na fila do batch
+// processor: retries mais curtos aliviam a memória com um collector instável,
+// ao custo de descartar mais dados.
+func retrySettings() retryConfig {
+	return retryConfig{
+		Enabled:         env.Bool("OTEL_EXPORTER_OTLP_RETRY_ENABLED", true),
+		InitialInterval: env.Duration("OTEL_EXPORTER_OTLP_RETRY_INITIAL_INTERVAL", 5*time.Second),
+		MaxInterval:     env.Duration("OTEL_EXPORTER_OTLP_RETRY_MAX_INTERVAL", 30*time.Second),
+		MaxElapsedTime:  env.Duration("OTEL_EXPORTER_OTLP_RETRY_MAX_ELAPSED_TIME", time.Minute),
+	}
+}
+
+// keepaliveParams configura os pings de keepalive da conexão gRPC com o
+// collector, evitando que load balancers derrubem conexões ociosas:
+//
+//   - OTEL_EXPORTER_OTLP_KEEPALIVE_TIME: intervalo sem atividade antes de um
+//     ping (padrão 5m, o mínimo aceito por servidores gRPC com a política
+//     padrão);
+//   - OTEL_EXPORTER_OTLP_KEEPALIVE_TIMEOUT: espera pela resposta do ping antes
+//     de fechar a conexão (padrão 20s);
+//   - OTEL_EXPORTER_OTLP_KEEPALIVE_PERMIT_WITHOUT_STREAM: envia pings mesmo sem
+//     exportação em andamento (padrão false).
+func keepaliveParams() keepalive.ClientParameters {
+	return keepalive.ClientParameters{
+		Time:                env.Duration("OTEL_EXPORTER_OTLP_KEEPALIVE_TIME", 5*time.Minute),
+		Timeout:             env.Duration("OTEL_EXPORTER_OTLP_KEEPALIVE_TIMEOUT", 20*time.Second),
+		PermitWithoutStream: env.Bool("OTEL_EXPORTER_OTLP_KEEPALIVE_PERMIT_WITHOUT_STREAM", false),
+	}
+}
+
+// temporalitySelector lê OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE
+// ("cumulative", o padrão, ou "delta"). Em delta, como define a especificação,
+// os UpDownCounters continuam cumulativos.
+func temporalitySelector() metric.TemporalitySelector {
+	switch preference := strings.ToLower(env.String("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", "cumulative")); preference {
+	case "cumulative":
+		return metric.DefaultTemporalitySelector
+	case "delta":
+		return func(kind metric.InstrumentKind) metricdata.Temporality {
+			switch kind {
+			case metric.InstrumentKindUpDownCounter, metric.InstrumentKindObservableUpDownCounter:
+				return metricdata.CumulativeTemporality
+			default:
+				return metricdata.DeltaTemporality
+			}
+		}
+	default:
+		log.Printf("⚠️ OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE inválido (%q), usando cumulative", preference)
+		return metric.DefaultTemporalitySelector
+	}
+}
+
+// otlpCompression lê OTEL_EXPORTER_OTLP_COMPRESSION ("gzip" ou "none", o
+// padrão) e retorna o nome do compressor gRPC, ou "" para nenhum.
+func otlpCompression() string {
+	switch compression := env.String("OTEL_EXPORTER_OTLP_COMPRESSION", "none"); compression {
+	case "gzip":
+		return "gzip"
+	case "none":
+		return ""
+	default:
+		log.Printf("⚠️ OTEL_EXPORTER_OTLP_COMPRESSION inválido (%q), exportando sem compressão", compression)
+		return ""
+	}
+}
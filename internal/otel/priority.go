@@ -0,0 +1,27 @@
+package otel
+
+import (
+	"time"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SamplingPriorityKey é o atributo usado pelo tail sampler do collector para
+// decidir quais traces manter.
+const SamplingPriorityKey = attribute.Key("sampling.priority")
+
+// samplingPriorityThreshold é a latência a partir da qual uma requisição é
+// considerada lenta (SAMPLING_PRIORITY_THRESHOLD).
+var samplingPriorityThreshold = env.Duration("SAMPLING_PRIORITY_THRESHOLD", 500*time.Millisecond)
+
+// MarkSamplingPriority define sampling.priority=1 no span quando a requisição
+// falhou ou demorou mais que o limite configurado. As apps continuam gravando
+// todos os spans; a decisão de descartar fica para o tail sampling.
+func MarkSamplingPriority(span trace.Span, elapsed time.Duration, failed bool) {
+	if failed || elapsed > samplingPriorityThreshold {
+		span.SetAttributes(SamplingPriorityKey.Int(1))
+	}
+}
@@ -0,0 +1,183 @@
+package otel
+
+import (
+	"context"
+	"sync"
+
+	"go-observability-lab/internal/semconv"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// pathFilterProcessor descarta, antes do export, os spans de servidor cujo
+// url.path está na lista de rotas ignoradas (OTEL_TRACE_IGNORE_PATHS), junto
+// com todos os spans filhos criados localmente sob eles.
+type pathFilterProcessor struct {
+	next  trace.SpanProcessor
+	paths map[string]struct{}
+
+	// ignored guarda os SpanIDs descartados que ainda não terminaram, para
+	// que seus filhos também sejam descartados.
+	ignored sync.Map
+}
+
+func newPathFilterProcessor(next trace.SpanProcessor, paths []string) *pathFilterProcessor {
+	p := &pathFilterProcessor{next: next, paths: make(map[string]struct{}, len(paths))}
+	for _, path := range paths {
+		p.paths[path] = struct{}{}
+	}
+	return p
+}
+
+func (p *pathFilterProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	if p.shouldIgnore(s) {
+		p.ignored.Store(s.SpanContext().SpanID(), struct{}{})
+		return
+	}
+	p.next.OnStart(parent, s)
+}
+
+func (p *pathFilterProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if _, ok := p.ignored.LoadAndDelete(s.SpanContext().SpanID()); ok {
+		return
+	}
+	p.next.OnEnd(s)
+}
+
+func (p *pathFilterProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *pathFilterProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+func (p *pathFilterProcessor) shouldIgnore(s trace.ReadWriteSpan) bool {
+	if parent := s.Parent(); parent.IsValid() && !parent.IsRemote() {
+		if _, ok := p.ignored.Load(parent.SpanID()); ok {
+			return true
+		}
+	}
+
+	if s.SpanKind() != oteltrace.SpanKindServer {
+		return false
+	}
+	for _, kv := range s.Attributes() {
+		if kv.Key == semconv.URLPathKey {
+			_, ok := p.paths[kv.Value.AsString()]
+			return ok
+		}
+	}
+	return false
+}
+
+// errorKeepingProcessor implementa a amostragem que sempre mantém erros: como o
+// sampler decide no início do span, antes de o status ser conhecido, todos os
+// spans são gravados e a razão de amostragem é aplicada aqui, no fim.
+//
+// A decisão vale para a árvore local inteira do trace: os spans ficam retidos,
+// agrupados pelo trace ID, até o último span local do trace terminar. Se algum
+// deles tem status de erro, é mais lento que SAMPLING_PRIORITY_THRESHOLD ou foi
+// forçado por OTEL_FORCE_SAMPLE_ROUTES ou pelo baggage ForceTraceBaggageKey,
+// todos são exportados; senão, só quando o trace ID cai dentro da razão. Assim
+// um span de erro nunca é exportado sem os pais locais, o que o deixaria órfão
+// no backend. Um span que nunca termina mantém o seu trace retido.
+type errorKeepingProcessor struct {
+	next  trace.SpanProcessor
+	ratio trace.Sampler
+
+	mu     sync.Mutex
+	traces map[oteltrace.TraceID]*localTrace
+}
+
+// localTrace acumula os spans locais já terminados de um trace enquanto
+// houver outros em andamento.
+type localTrace struct {
+	open  int
+	keep  bool
+	spans []trace.ReadOnlySpan
+}
+
+func newErrorKeepingProcessor(next trace.SpanProcessor, ratio float64) *errorKeepingProcessor {
+	return &errorKeepingProcessor{
+		next:   next,
+		ratio:  sharedRatioSampler(ratio),
+		traces: make(map[oteltrace.TraceID]*localTrace),
+	}
+}
+
+func (p *errorKeepingProcessor) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	id := s.SpanContext().TraceID()
+
+	p.mu.Lock()
+	t, ok := p.traces[id]
+	if !ok {
+		t = &localTrace{}
+		p.traces[id] = t
+	}
+	t.open++
+	p.mu.Unlock()
+
+	p.next.OnStart(parent, s)
+}
+
+func (p *errorKeepingProcessor) OnEnd(s trace.ReadOnlySpan) {
+	id := s.SpanContext().TraceID()
+
+	p.mu.Lock()
+	t, ok := p.traces[id]
+	if !ok {
+		t = &localTrace{open: 1}
+	}
+	t.spans = append(t.spans, s)
+	t.keep = t.keep || mustKeep(s)
+	t.open--
+	if t.open > 0 {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.traces, id)
+	p.mu.Unlock()
+
+	if !t.keep && !p.sampled(id) {
+		return
+	}
+	for _, span := range t.spans {
+		p.next.OnEnd(span)
+	}
+}
+
+func (p *errorKeepingProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *errorKeepingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampled informa se o trace cai dentro da razão de amostragem em uso.
+func (p *errorKeepingProcessor) sampled(id oteltrace.TraceID) bool {
+	result := p.ratio.ShouldSample(trace.SamplingParameters{TraceID: id})
+	return result.Decision == trace.RecordAndSample
+}
+
+// mustKeep informa se s obriga a exportar o seu trace, fora da razão.
+func mustKeep(s trace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error || s.EndTime().Sub(s.StartTime()) > samplingPriorityThreshold {
+		return true
+	}
+	return isForced(s)
+}
+
+// isForced informa se s foi amostrado à força pelo routeSampler ou pelo
+// baggageSampler.
+func isForced(s trace.ReadOnlySpan) bool {
+	for _, kv := range s.Attributes() {
+		if kv.Key == forcedSampleKey {
+			return kv.Value.AsBool()
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestErrorKeepingProcessorKeepsLocalTree(t *testing.T) {
+	tests := []struct {
+		name      string
+		failChild bool
+		wantSpans int
+	}{
+		{name: "erro no filho mantém o pai", failChild: true, wantSpans: 2},
+		{name: "sem erro fora da razão", failChild: false, wantSpans: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			// Razão 0: só um erro justifica exportar o trace
+			processor := &errorKeepingProcessor{
+				next:   recorder,
+				ratio:  trace.TraceIDRatioBased(0),
+				traces: make(map[oteltrace.TraceID]*localTrace),
+			}
+			tracerProvider := trace.NewTracerProvider(trace.WithSpanProcessor(processor))
+			tracer := tracerProvider.Tracer("test")
+
+			ctx, root := tracer.Start(context.Background(), "root")
+			_, child := tracer.Start(ctx, "child")
+			if tt.failChild {
+				child.RecordError(errors.New("falha"))
+				child.SetStatus(codes.Error, "falha")
+			}
+			// O filho termina antes do pai, como em um handler real
+			child.End()
+			if got := len(recorder.Ended()); got != 0 {
+				t.Fatalf("spans exportados antes do fim da raiz = %d, esperado 0", got)
+			}
+			root.End()
+
+			spans := recorder.Ended()
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("spans = %d, esperado %d", len(spans), tt.wantSpans)
+			}
+			if len(processor.traces) != 0 {
+				t.Errorf("traces retidos = %d, esperado 0", len(processor.traces))
+			}
+			if tt.wantSpans == 0 {
+				return
+			}
+			if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+				t.Errorf("pai de %s = %s, esperado %s", spans[0].Name(), spans[0].Parent().SpanID(), spans[1].SpanContext().SpanID())
+			}
+		})
+	}
+}
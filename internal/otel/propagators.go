@@ -0,0 +1,58 @@
+package otel
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/contrib/propagators/jaeger"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// propagators é o registro de propagators disponíveis em OTEL_PROPAGATORS,
+// indexado pelo nome em minúsculas.
+var (
+	propagatorsMu sync.RWMutex
+	propagators   = map[string]propagation.TextMapPropagator{
+		"tracecontext": propagation.TraceContext{},
+		"baggage":      propagation.Baggage{},
+		"b3":           b3.New(b3.WithInjectEncoding(b3.B3SingleHeader)),
+		"b3multi":      b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
+		"jaeger":       jaeger.Jaeger{},
+	}
+)
+
+// RegisterPropagator torna p selecionável pelo nome em OTEL_PROPAGATORS, por
+// exemplo para um esquema interno de correlação. Deve ser chamada antes de
+// SetupProviders; registrar um nome existente substitui o anterior.
+func RegisterPropagator(name string, p propagation.TextMapPropagator) {
+	propagatorsMu.Lock()
+	defer propagatorsMu.Unlock()
+
+	propagators[strings.ToLower(name)] = p
+}
+
+// newPropagator monta o propagator composto a partir de OTEL_PROPAGATORS
+// (separados por vírgula). O padrão "tracecontext,baggage" preserva o
+// comportamento original; valores desconhecidos são ignorados com um aviso.
+func newPropagator() propagation.TextMapPropagator {
+	names := env.List("OTEL_PROPAGATORS", []string{"tracecontext", "baggage"})
+
+	propagatorsMu.RLock()
+	defer propagatorsMu.RUnlock()
+
+	var selected []propagation.TextMapPropagator
+	for _, name := range names {
+		p, ok := propagators[strings.ToLower(name)]
+		if !ok {
+			log.Printf("⚠️ Propagator desconhecido em OTEL_PROPAGATORS ignorado: %q", name)
+			continue
+		}
+		selected = append(selected, p)
+	}
+
+	return propagation.NewCompositeTextMapPropagator(selected...)
+}
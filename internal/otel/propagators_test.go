@@ -0,0 +1,79 @@
+package otel
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceContextExtraction(t *testing.T) {
+	// Valida a configuração padrão, independente do ambiente de quem roda o teste
+	t.Setenv("OTEL_PROPAGATORS", "")
+
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		name        string
+		traceparent string
+		wantParent  bool
+	}{
+		{
+			name:        "traceparent válido",
+			traceparent: "00-" + traceID + "-" + parentID + "-01",
+			wantParent:  true,
+		},
+		{
+			name:        "traceparent malformado",
+			traceparent: "00-" + traceID + "-nao-e-um-span-id-01",
+			wantParent:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracerProvider := trace.NewTracerProvider(trace.WithSpanProcessor(recorder))
+
+			handler := otelhttp.NewHandler(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+				"/",
+				otelhttp.WithTracerProvider(tracerProvider),
+				otelhttp.WithPropagators(newPropagator()),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("traceparent", tt.traceparent)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("spans = %d, esperado 1", len(spans))
+			}
+			span := spans[0]
+
+			if !tt.wantParent {
+				if span.Parent().IsValid() {
+					t.Errorf("parent = %s, esperado um span raiz", span.Parent().SpanID())
+				}
+				return
+			}
+
+			if got := span.SpanContext().TraceID().String(); got != traceID {
+				t.Errorf("trace ID = %s, esperado %s", got, traceID)
+			}
+			if got := span.Parent().SpanID().String(); got != parentID {
+				t.Errorf("parent span ID = %s, esperado %s", got, parentID)
+			}
+			if !span.Parent().IsRemote() {
+				t.Error("parent deveria ser remoto")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package otel
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// samplingRatio guarda a razão de amostragem em uso, compartilhada pelo
+// sampler e pelo errorKeepingProcessor. É instalada na configuração do
+// TracerProvider, qualquer que seja a razão inicial.
+var samplingRatio atomic.Pointer[ratioSampler]
+
+// ratioSampler é um TraceIDRatioBased cuja razão pode ser trocada em tempo de
+// execução com SetSamplingRatio.
+type ratioSampler struct {
+	ratio   atomic.Pointer[ratioState]
+	initial float64
+}
+
+type ratioState struct {
+	ratio   float64
+	sampler trace.Sampler
+}
+
+func newRatioSampler(ratio float64) *ratioSampler {
+	s := &ratioSampler{initial: ratio}
+	s.set(ratio)
+	return s
+}
+
+func (s *ratioSampler) set(ratio float64) {
+	s.ratio.Store(&ratioState{ratio: ratio, sampler: trace.TraceIDRatioBased(ratio)})
+}
+
+func (s *ratioSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	return s.ratio.Load().sampler.ShouldSample(p)
+}
+
+func (s *ratioSampler) Description() string {
+	return fmt.Sprintf("DynamicRatioSampler{%g}", s.ratio.Load().ratio)
+}
+
+// sharedRatioSampler retorna o ratioSampler do processo, criando-o com ratio na
+// primeira chamada.
+func sharedRatioSampler(ratio float64) *ratioSampler {
+	samplingRatio.CompareAndSwap(nil, newRatioSampler(ratio))
+	return samplingRatio.Load()
+}
+
+// SetSamplingRatio altera a razão de amostragem em uso, por exemplo para
+// amostrar 100% durante um incidente sem redeploy, ou para reduzir o volume de
+// um serviço que subiu com razão 1.
+func SetSamplingRatio(ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("razão de amostragem inválida: %g (use um valor entre 0 e 1)", ratio)
+	}
+	s := sharedRatioSampler(ratio)
+
+	previous := s.ratio.Load().ratio
+	s.set(ratio)
+	log.Printf("🎚️ Razão de amostragem alterada de %g para %g (inicial: %g)", previous, ratio, s.initial)
+	return nil
+}
+
+// SamplingRatio retorna a razão de amostragem em uso, ou 1 antes da
+// configuração do TracerProvider.
+func SamplingRatio() float64 {
+	if s := samplingRatio.Load(); s != nil {
+		return s.ratio.Load().ratio
+	}
+	return 1
+}
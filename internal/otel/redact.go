@@ -0,0 +1,103 @@
+package otel
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/semconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// redactedValue substitui o valor dos parâmetros redigidos.
+const redactedValue = "***"
+
+// redactParams são os parâmetros de query cujos valores nunca devem chegar aos
+// spans (REDACT_QUERY_PARAMS, separados por vírgula, sem diferenciar
+// maiúsculas), como token,api_key,password.
+var redactParams = newParamSet(env.List("REDACT_QUERY_PARAMS", nil))
+
+func newParamSet(params []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(params))
+	for _, p := range params {
+		set[strings.ToLower(p)] = struct{}{}
+	}
+	return set
+}
+
+// urlAttributes são os atributos que podem carregar uma query string.
+var urlAttributes = []attribute.Key{
+	semconv.URLFullKey,
+	semconv.URLQueryKey,
+	"http.url",
+	"http.target",
+}
+
+// RedactURL substitui por "***" os valores dos parâmetros de
+// REDACT_QUERY_PARAMS em raw, que pode ser uma URL completa, um caminho com
+// query ou só a query. O restante é mantido como está, inclusive a ordem dos
+// parâmetros.
+func RedactURL(raw string) string {
+	if len(redactParams) == 0 {
+		return raw
+	}
+
+	prefix, query, ok := strings.Cut(raw, "?")
+	if !ok {
+		// Sem "?", só url.query traz a query pura; caminhos não têm "="
+		if !strings.Contains(raw, "=") {
+			return raw
+		}
+		prefix, query = "", raw
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		name, _, hasValue := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(name)
+		if err != nil {
+			key = name
+		}
+		if _, redact := redactParams[strings.ToLower(key)]; redact && hasValue {
+			pairs[i] = name + "=" + redactedValue
+		}
+	}
+
+	redacted := strings.Join(pairs, "&")
+	if ok {
+		redacted = prefix + "?" + redacted
+	}
+	if hasFragment {
+		redacted += "#" + fragment
+	}
+	return redacted
+}
+
+// redactingProcessor aplica RedactURL aos atributos de URL de cada span no
+// início, antes que qualquer exporter os veja. Cobre os atributos definidos
+// na criação do span, como os do otelhttp e do httpclient; quem define URLs
+// depois deve usar RedactURL diretamente.
+type redactingProcessor struct{}
+
+func (redactingProcessor) OnStart(_ context.Context, s trace.ReadWriteSpan) {
+	for _, kv := range s.Attributes() {
+		for _, key := range urlAttributes {
+			if kv.Key != key {
+				continue
+			}
+			if v := kv.Value.AsString(); RedactURL(v) != v {
+				s.SetAttributes(key.String(RedactURL(v)))
+			}
+		}
+	}
+}
+
+func (redactingProcessor) OnEnd(trace.ReadOnlySpan) {}
+
+func (redactingProcessor) Shutdown(context.Context) error { return nil }
+
+func (redactingProcessor) ForceFlush(context.Context) error { return nil }
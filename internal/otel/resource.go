@@ -0,0 +1,58 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go-observability-lab/internal/buildinfo"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/semconv"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// instanceID identifica esta instância do processo e permanece estável
+// durante toda a sua vida, permitindo diferenciar réplicas do mesmo serviço.
+var instanceID = uuid.NewString()
+
+// buildResource monta o resource compartilhado pelos sinais de traces,
+// métricas e logs. Os atributos de OTEL_RESOURCE_ATTRIBUTES (chave=valor
+// separados por vírgula) têm precedência sobre os definidos no código.
+//
+// SERVICE_NAMESPACE, quando definida, agrupa os serviços em service.namespace.
+// No Kubernetes, POD_NAME, POD_NAMESPACE e NODE_NAME, injetadas pela Downward
+// API, viram k8s.pod.name, k8s.namespace.name e k8s.node.name.
+func buildResource(serviceName string) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(serviceName),
+		semconv.ServiceVersionKey.String(buildinfo.ServiceVersion()),
+		semconv.ServiceInstanceIDKey.String(instanceID),
+	}
+	if namespace := env.String("SERVICE_NAMESPACE", ""); namespace != "" {
+		attrs = append(attrs, semconv.ServiceNamespaceKey.String(namespace))
+	}
+	for key, name := range map[attribute.Key]string{
+		semconv.K8SPodNameKey:       "POD_NAME",
+		semconv.K8SNamespaceNameKey: "POD_NAMESPACE",
+		semconv.K8SNodeNameKey:      "NODE_NAME",
+	} {
+		if v := env.String(name, ""); v != "" {
+			attrs = append(attrs, key.String(v))
+		}
+	}
+	defaults := resource.NewWithAttributes(semconv.SchemaURL(), attrs...)
+
+	fromEnv, err := resource.New(context.Background(), resource.WithFromEnv())
+	if err != nil {
+		if !errors.Is(err, resource.ErrPartialResource) {
+			return nil, err
+		}
+		// Entradas malformadas são descartadas, mas as válidas ainda são usadas
+		log.Printf("⚠️ OTEL_RESOURCE_ATTRIBUTES parcialmente inválido: %v", err)
+	}
+
+	return resource.Merge(defaults, fromEnv)
+}
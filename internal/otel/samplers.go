@@ -0,0 +1,134 @@
+package otel
+
+import (
+	"log"
+	"strings"
+
+	"go-observability-lab/internal/config"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/semconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// forcedSampleKey marca os spans amostrados à força (por OTEL_FORCE_SAMPLE_ROUTES
+// ou pelo baggage ForceTraceBaggageKey), para que o errorKeepingProcessor
+// mantenha o trace inteiro.
+const forcedSampleKey = attribute.Key("sampling.forced")
+
+// ForceTraceBaggageKey é a chave de baggage que força a amostragem de uma
+// requisição em todos os serviços da cadeia: com trace.force=1, cada span
+// criado sob esse contexto é amostrado, independente da razão configurada e da
+// decisão do pai. Como o baggage é propagado junto com o trace context, basta
+// definir a chave no primeiro serviço para rastrear a requisição de ponta a
+// ponta. No App A, o header baggage da requisição original só pode trazer a
+// chave com o token administrativo (veja middleware.ForceTrace).
+const ForceTraceBaggageKey = "trace.force"
+
+// newSampler escolhe o sampler a partir de cfg e de OTEL_TRACES_SAMPLER, que
+// segue os valores da especificação: always_off e parentbased_always_off
+// descartam tudo; always_on, traceidratio e as variantes parentbased_ (o
+// padrão é parentbased_traceidratio) usam a razão de OTEL_TRACES_SAMPLER_ARG,
+// ou 1 com always_on. Como o sampler explícito substitui o que o SDK leria do
+// ambiente, a variável é interpretada aqui.
+//
+// A razão fica sempre em um ratioSampler compartilhado, mesmo quando é 1, para
+// que possa ser alterada em tempo de execução com SetSamplingRatio (exceto com
+// always_off). O baggage ForceTraceBaggageKey e as rotas de
+// OTEL_FORCE_SAMPLE_ROUTES forçam a amostragem independente dela.
+//
+// Com OTEL_TRACES_KEEP_ERRORS=true, tudo é gravado e a razão é aplicada pelo
+// errorKeepingProcessor no fim de cada span.
+func newSampler(cfg config.Config) trace.Sampler {
+	parentBased := true
+	switch name := env.String("OTEL_TRACES_SAMPLER", ""); name {
+	case "", "parentbased_traceidratio":
+	case "traceidratio":
+		parentBased = false
+	case "always_on", "parentbased_always_on":
+		cfg.SamplingRatio = 1
+		parentBased = name == "parentbased_always_on"
+	case "always_off":
+		return trace.NeverSample()
+	case "parentbased_always_off":
+		return trace.ParentBased(trace.NeverSample())
+	default:
+		log.Printf("⚠️ OTEL_TRACES_SAMPLER não suportado: %q, usando parentbased_traceidratio", name)
+	}
+
+	var base trace.Sampler = sharedRatioSampler(cfg.SamplingRatio)
+	if keepErrors() {
+		base = trace.AlwaysSample()
+	}
+	if parentBased {
+		base = trace.ParentBased(base)
+	}
+
+	if routes := env.List("OTEL_FORCE_SAMPLE_ROUTES", nil); len(routes) > 0 {
+		base = routeSampler{routes: routes, base: base}
+	}
+	return baggageSampler{base: base}
+}
+
+// baggageSampler amostra sempre que o contexto pai carrega
+// ForceTraceBaggageKey=1 e delega os demais casos para base.
+type baggageSampler struct {
+	base trace.Sampler
+}
+
+func (s baggageSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if baggage.FromContext(p.ParentContext).Member(ForceTraceBaggageKey).Value() == "1" {
+		return trace.SamplingResult{
+			Decision:   trace.RecordAndSample,
+			Attributes: []attribute.KeyValue{forcedSampleKey.Bool(true)},
+			Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+func (s baggageSampler) Description() string {
+	return "BaggageSampler{" + ForceTraceBaggageKey + "}+" + s.base.Description()
+}
+
+// routeSampler sempre amostra os spans de servidor cujo url.path está em
+// routes (um caminho exato ou um prefixo terminado em "*") e delega os demais
+// para base.
+type routeSampler struct {
+	routes []string
+	base   trace.Sampler
+}
+
+func (s routeSampler) ShouldSample(p trace.SamplingParameters) trace.SamplingResult {
+	if p.Kind == oteltrace.SpanKindServer && s.matches(p.Attributes) {
+		return trace.SamplingResult{
+			Decision:   trace.RecordAndSample,
+			Attributes: []attribute.KeyValue{forcedSampleKey.Bool(true)},
+			Tracestate: oteltrace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+	return s.base.ShouldSample(p)
+}
+
+func (s routeSampler) Description() string {
+	return "RouteSampler{" + strings.Join(s.routes, ",") + "}+" + s.base.Description()
+}
+
+func (s routeSampler) matches(attrs []attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if kv.Key != semconv.URLPathKey {
+			continue
+		}
+		path := kv.Value.AsString()
+		for _, route := range s.routes {
+			if prefix, ok := strings.CutSuffix(route, "*"); (ok && strings.HasPrefix(path, prefix)) || path == route {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
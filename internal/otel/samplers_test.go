@@ -0,0 +1,50 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"go-observability-lab/internal/config"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewSamplerHonorsEnvSampler(t *testing.T) {
+	tests := []struct {
+		sampler   string
+		ratio     float64
+		wantSpans int
+	}{
+		{sampler: "always_off", ratio: 1, wantSpans: 0},
+		{sampler: "parentbased_always_off", ratio: 1, wantSpans: 0},
+		{sampler: "traceidratio", ratio: 0, wantSpans: 0},
+		{sampler: "always_on", ratio: 0, wantSpans: 1},
+		{sampler: "", ratio: 1, wantSpans: 1},
+	}
+
+	for _, tt := range tests {
+		name := tt.sampler
+		if name == "" {
+			name = "padrão"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("OTEL_TRACES_SAMPLER", tt.sampler)
+			// A razão é compartilhada pelo processo; cada caso parte do zero
+			samplingRatio.Store(nil)
+			t.Cleanup(func() { samplingRatio.Store(nil) })
+
+			recorder := tracetest.NewSpanRecorder()
+			tracerProvider := trace.NewTracerProvider(
+				trace.WithSampler(newSampler(config.Config{SamplingRatio: tt.ratio})),
+				trace.WithSpanProcessor(recorder),
+			)
+			_, span := tracerProvider.Tracer("test").Start(context.Background(), "root")
+			span.End()
+
+			if got := len(recorder.Ended()); got != tt.wantSpans {
+				t.Errorf("spans = %d, esperado %d", got, tt.wantSpans)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go-observability-lab/internal/attrs"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartServerSpan inicia o span de um handler já com os atributos HTTP padrão
+// do lab (http.method e http.path), extraídos de r. Se ctx tiver deadline, o
+// tempo restante na entrada é registrado em context.deadline_remaining_ms.
+func StartServerSpan(ctx context.Context, tracer trace.Tracer, r *http.Request, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	kvs := []attribute.KeyValue{
+		attrs.HTTPMethod(r.Method),
+		attrs.HTTPPath(r.URL.Path),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		kvs = append(kvs, attrs.DeadlineRemaining(time.Until(deadline).Milliseconds()))
+	}
+	return tracer.Start(ctx, name, append(opts, trace.WithAttributes(kvs...))...)
+}
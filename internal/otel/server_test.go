@@ -0,0 +1,40 @@
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// BenchmarkStartServerSpan mede o custo de StartServerSpan com um provider que
+// grava os spans sem exportá-los, com e sem deadline no contexto.
+func BenchmarkStartServerSpan(b *testing.B) {
+	tracerProvider := trace.NewTracerProvider(trace.WithSampler(trace.AlwaysSample()))
+	b.Cleanup(func() { tracerProvider.Shutdown(context.Background()) })
+	tracer := tracerProvider.Tracer("bench")
+
+	r := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+
+	b.Run("sem deadline", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, span := StartServerSpan(context.Background(), tracer, r, "handleRoot")
+			span.End()
+		}
+	})
+
+	b.Run("com deadline", func(b *testing.B) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		b.ReportAllocs()
+		for b.Loop() {
+			_, span := StartServerSpan(ctx, tracer, r, "handleRoot")
+			span.End()
+		}
+	})
+}
@@ -3,126 +3,488 @@ package otel
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"strings"
 	"time"
 
+	"go-observability-lab/internal/config"
+	"go-observability-lab/internal/env"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/log/global"
-	"go.opentelemetry.io/otel/propagation"
+	lognoop "go.opentelemetry.io/otel/log/noop"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
 	otellog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// Providers agrupa os providers instalados por SetupProviders, permitindo
+// forçar o flush da telemetria (por exemplo, em testes de integração) antes de
+// encerrá-los. Um provider pode ser nil quando o sinal foi desabilitado.
+type Providers struct {
+	TracerProvider *trace.TracerProvider
+	MeterProvider  *metric.MeterProvider
+	LoggerProvider *otellog.LoggerProvider
+
+	// MetricsHandler só é definido quando OTEL_METRICS_EXPORTER=prometheus e
+	// deve ser registrado pelas apps em MetricsPath.
+	MetricsHandler http.Handler
+	MetricsPath    string
+
+	shutdownFuncs []func(context.Context) error
+}
+
+// ForceFlush exporta imediatamente toda a telemetria pendente nos providers.
+func (p *Providers) ForceFlush(ctx context.Context) error {
+	var err error
+	if p.TracerProvider != nil {
+		err = errors.Join(err, p.TracerProvider.ForceFlush(ctx))
+	}
+	if p.MeterProvider != nil {
+		err = errors.Join(err, p.MeterProvider.ForceFlush(ctx))
+	}
+	if p.LoggerProvider != nil {
+		err = errors.Join(err, p.LoggerProvider.ForceFlush(ctx))
+	}
+	return err
+}
+
+// Shutdown encerra os providers na ordem em que foram criados. Chamadas
+// subsequentes não têm efeito.
+func (p *Providers) Shutdown(ctx context.Context) error {
+	var err error
+	for _, fn := range p.shutdownFuncs {
+		err = errors.Join(err, fn(ctx))
+	}
+	p.shutdownFuncs = nil
+	return err
+}
+
 // SetupOTelSDK inicializa o pipeline do OpenTelemetry para um serviço específico
 func SetupOTelSDK(ctx context.Context, serviceName string, otlpEndpoint string) (func(context.Context) error, error) {
-	var shutdownFuncs []func(context.Context) error
-	var err error
+	cfg, err := config.Load(serviceName)
+	if err != nil {
+		return func(context.Context) error { return nil }, err
+	}
+	if otlpEndpoint != "" {
+		cfg.Endpoint = otlpEndpoint
+	}
 
-	shutdown := func(ctx context.Context) error {
-		var err error
-		for _, fn := range shutdownFuncs {
-			err = errors.Join(err, fn(ctx))
-		}
-		shutdownFuncs = nil
-		return err
+	providers, err := SetupProviders(ctx, cfg)
+	if err != nil {
+		return func(context.Context) error { return nil }, err
 	}
+	return providers.Shutdown, nil
+}
 
-	handleErr := func(inErr error) {
-		err = errors.Join(inErr, shutdown(ctx))
+// SetupProviders inicializa o pipeline do OpenTelemetry a partir de cfg e
+// retorna os providers instalados globalmente. Em caso de erro, o que já havia
+// sido criado é encerrado antes do retorno.
+//
+// Falhas ao criar os providers de métricas ou de logs não derrubam os demais
+// sinais; com TELEMETRY_STRICT=true, qualquer falha aborta a inicialização.
+// Com OTEL_SDK_DISABLED=true, apenas providers no-op são instalados.
+func SetupProviders(ctx context.Context, cfg config.Config) (*Providers, error) {
+	serviceName := cfg.ServiceName
+	otlpEndpoint := cfg.Endpoint
+	if otlpEndpoint == "" {
+		otlpEndpoint = "localhost:4317"
 	}
 
+	providers := &Providers{}
+
 	// Inicializa o Propagator
-	prop := propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	)
-	otel.SetTextMapPropagator(prop)
+	otel.SetTextMapPropagator(newPropagator())
 
-	// Inicializa o Trace Provider
-	tracerProvider, err := newTracerProvider(serviceName, otlpEndpoint)
+	// Kill-switch da especificação: nenhum exporter é criado e os providers
+	// globais viram no-op. O contexto recebido continua sendo propagado.
+	if env.Bool("OTEL_SDK_DISABLED", false) {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetMeterProvider(metricnoop.NewMeterProvider())
+		global.SetLoggerProvider(lognoop.NewLoggerProvider())
+		log.Printf("⏸️ OTEL_SDK_DISABLED=true: telemetria desabilitada para serviço: %s", serviceName)
+		return providers, nil
+	}
+
+	handleErr := func(inErr error) error {
+		return errors.Join(inErr, providers.Shutdown(ctx))
+	}
+
+	res, err := buildResource(serviceName)
 	if err != nil {
-		handleErr(err)
-		return shutdown, err
+		return nil, handleErr(err)
+	}
+
+	// Inicializa o Trace Provider. Com TELEMETRY_OPTIONAL=true, uma falha na
+	// criação do exporter não impede a app de subir: os traces ficam
+	// desabilitados (no-op) e providers.TracerProvider permanece nil.
+	tracerProvider, err := newTracerProvider(res, signalEndpoint("TRACES", otlpEndpoint), cfg)
+	switch {
+	case err == nil:
+		providers.TracerProvider = tracerProvider
+		providers.shutdownFuncs = append(providers.shutdownFuncs, tracerProvider.Shutdown)
+		otel.SetTracerProvider(tracerProvider)
+	case env.Bool("TELEMETRY_OPTIONAL", false):
+		log.Printf("⚠️ TELEMETRY_OPTIONAL=true: seguindo sem exportar traces: %v", err)
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	default:
+		return nil, handleErr(err)
 	}
-	shutdownFuncs = append(shutdownFuncs, tracerProvider.Shutdown)
-	otel.SetTracerProvider(tracerProvider)
+
+	// Métricas e logs degradam de forma independente: sem TELEMETRY_STRICT=true,
+	// uma falha em um desses sinais é registrada e a app segue com os demais.
+	strict := env.Bool("TELEMETRY_STRICT", false)
+	enabled := []string{}
+	if providers.TracerProvider != nil {
+		enabled = append(enabled, "traces")
+	}
+	var degraded []string
 
 	// Inicializa o Meter Provider
-	meterProvider, err := newMeterProvider()
-	if err != nil {
-		handleErr(err)
-		return shutdown, err
+	meterProvider, metricsHandler, err := newMeterProvider(res, signalEndpoint("METRICS", otlpEndpoint))
+	if err == nil && env.Bool("ENABLE_RUNTIME_METRICS", false) {
+		// Métricas do runtime do Go (GC, goroutines, heap), opcionais
+		if err = runtime.Start(runtime.WithMeterProvider(meterProvider)); err != nil {
+			log.Printf("❌ Erro ao iniciar métricas de runtime: %v", err)
+			err = errors.Join(err, meterProvider.Shutdown(ctx))
+		}
+	}
+	switch {
+	case err == nil:
+		providers.MeterProvider = meterProvider
+		if metricsHandler != nil {
+			providers.MetricsHandler = metricsHandler
+			providers.MetricsPath = env.String("OTEL_EXPORTER_PROMETHEUS_PATH", "/metrics")
+		}
+		providers.shutdownFuncs = append(providers.shutdownFuncs, meterProvider.Shutdown)
+		otel.SetMeterProvider(meterProvider)
+		enabled = append(enabled, "metrics")
+	case strict:
+		return nil, handleErr(err)
+	default:
+		log.Printf("⚠️ Seguindo sem exportar métricas: %v", err)
+		degraded = append(degraded, "metrics")
 	}
-	shutdownFuncs = append(shutdownFuncs, meterProvider.Shutdown)
-	otel.SetMeterProvider(meterProvider)
 
 	// Inicializa o Logger Provider
-	loggerProvider, err := newLoggerProvider()
-	if err != nil {
-		handleErr(err)
-		return shutdown, err
+	loggerProvider, err := newLoggerProvider(res, signalEndpoint("LOGS", otlpEndpoint))
+	switch {
+	case err == nil:
+		providers.LoggerProvider = loggerProvider
+		providers.shutdownFuncs = append(providers.shutdownFuncs, loggerProvider.Shutdown)
+		global.SetLoggerProvider(loggerProvider)
+		enabled = append(enabled, "logs")
+	case strict:
+		return nil, handleErr(err)
+	default:
+		log.Printf("⚠️ Seguindo sem exportar logs: %v", err)
+		degraded = append(degraded, "logs")
 	}
-	shutdownFuncs = append(shutdownFuncs, loggerProvider.Shutdown)
-	global.SetLoggerProvider(loggerProvider)
 
-	log.Printf("✅ OpenTelemetry configurado para serviço: %s", serviceName)
-	return shutdown, err
+	if providers.TracerProvider != nil && env.Bool("OTEL_STARTUP_CANARY", false) {
+		emitCanary(ctx, providers.TracerProvider)
+	}
+
+	log.Printf("✅ OpenTelemetry configurado para serviço: %s (sinais ativos: %s)", serviceName, strings.Join(enabled, ", "))
+	if len(degraded) > 0 {
+		log.Printf("⚠️ Sinais desabilitados por falha na inicialização: %s", strings.Join(degraded, ", "))
+	}
+	return providers, nil
 }
 
-func newTracerProvider(serviceName, endpoint string) (*trace.TracerProvider, error) {
-	if endpoint == "" {
-		endpoint = "localhost:4317"
+// newTracerProvider cria o TracerProvider exportando para endpoint ou, quando
+// OTEL_EXPORTER_OTLP_ENDPOINTS estiver definida, para cada um dos endpoints
+// listados (útil durante migrações de collector). Cada endpoint tem seu próprio
+// batcher, e a falha de um não impede os demais. Com OTEL_TRACES_EXPORTER=file,
+// os spans vão para um arquivo local em vez do collector; com console (ou
+// stdout), para o stdout; com none, não são exportados. Com
+// OTEL_SPAN_METRICS_ENABLED=true, os spans de servidor também geram métricas
+// RED (veja spanMetricsProcessor). Os atributos de URL passam por RedactURL.
+func newTracerProvider(res *resource.Resource, endpoint string, cfg config.Config) (*trace.TracerProvider, error) {
+	opts := []trace.TracerProviderOption{
+		trace.WithResource(res),
+		trace.WithSpanLimits(spanLimits()),
 	}
 
-	otlpExporter, err := otlptracegrpc.New(
-		context.Background(),
-		otlptracegrpc.WithEndpoint(endpoint),
-		otlptracegrpc.WithInsecure(),
-	)
+	// Antes dos processors, para que o errorKeepingProcessor compartilhe a
+	// razão resolvida pelo sampler
+	sampler := newSampler(cfg)
+
+	// Roda antes dos batchers, para que nenhum exporter veja os valores
+	if len(redactParams) > 0 {
+		opts = append(opts, trace.WithSpanProcessor(redactingProcessor{}))
+	}
+
+	switch exporter := env.String("OTEL_TRACES_EXPORTER", "otlp"); exporter {
+	case "otlp":
+		processors, err := newOTLPSpanProcessors(endpoint, cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, processor := range processors {
+			opts = append(opts, trace.WithSpanProcessor(processor))
+		}
+	case "file":
+		fileExporter, err := newFileSpanExporter()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, trace.WithSpanProcessor(wrapSpanProcessor(fileExporter, cfg)))
+	case "console", "stdout":
+		consoleExporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, trace.WithSpanProcessor(wrapSpanProcessor(consoleExporter, cfg)))
+	case "none":
+		// Os spans continuam sendo criados e propagados, mas não são exportados
+		log.Printf("📭 OTEL_TRACES_EXPORTER=none: traces não serão exportados")
+	default:
+		return nil, fmt.Errorf("OTEL_TRACES_EXPORTER inválido: %q (use otlp, file, console, stdout ou none)", exporter)
+	}
+	if env.Bool("OTEL_SPAN_METRICS_ENABLED", false) {
+		opts = append(opts, trace.WithSpanProcessor(newSpanMetricsProcessor()))
+	}
+	opts = append(opts, trace.WithSampler(sampler))
+
+	tracerProvider := trace.NewTracerProvider(opts...)
+
+	return tracerProvider, nil
+}
+
+// newOTLPSpanProcessors cria um span processor para endpoint ou, quando
+// definida, para cada endpoint de OTEL_EXPORTER_OTLP_ENDPOINTS. Só falha se
+// nenhum deles puder ser criado.
+func newOTLPSpanProcessors(endpoint string, cfg config.Config) ([]trace.SpanProcessor, error) {
+	var processors []trace.SpanProcessor
+	var errs error
+	// O fallback vale só para o primeiro endpoint, o collector principal; os
+	// demais são destinos extras, que não devem duplicar spans no backup
+	fallback := env.String("OTEL_EXPORTER_OTLP_ENDPOINT_FALLBACK", "")
+	for i, ep := range env.List("OTEL_EXPORTER_OTLP_ENDPOINTS", []string{endpoint}) {
+		if i > 0 {
+			fallback = ""
+		}
+		processor, err := newSpanProcessor(ep, fallback, cfg)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		processors = append(processors, processor)
+	}
+	if len(processors) == 0 {
+		return nil, errs
+	}
+	return processors, nil
+}
+
+// keepErrors indica se spans com erro ou lentos devem ser mantidos mesmo fora
+// da razão de amostragem (OTEL_TRACES_KEEP_ERRORS, desligado por padrão).
+func keepErrors() bool {
+	return env.Bool("OTEL_TRACES_KEEP_ERRORS", false)
+}
+
+// newSpanProcessor cria o exporter OTLP de endpoint e o batch span processor
+// que o alimenta. Com fallback, o exporter passa a enviar para esse endpoint
+// quando endpoint falha seguidamente (veja failoverSpanExporter).
+func newSpanProcessor(endpoint, fallback string, cfg config.Config) (trace.SpanProcessor, error) {
+	primary, err := newTraceExporter(endpoint, cfg)
 	if err != nil {
-		log.Printf("❌ Erro ao criar OTLP exporter: %v", err)
 		return nil, err
 	}
 
-	tracerProvider := trace.NewTracerProvider(
-		trace.WithBatcher(otlpExporter,
-			trace.WithBatchTimeout(time.Second)),
-		trace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceNameKey.String(serviceName),
-		)),
-	)
+	var exporter trace.SpanExporter = primary
+	if fallback != "" {
+		secondary, err := newTraceExporter(fallback, cfg)
+		if err != nil {
+			log.Printf("⚠️ Fallback OTLP %s indisponível, exportando só para %s: %v", fallback, endpoint, err)
+		} else {
+			exporter = newFailoverSpanExporter(endpoint, primary, fallback, secondary)
+		}
+	}
+	return wrapSpanProcessor(exporter, cfg), nil
+}
 
-	return tracerProvider, nil
+// wrapSpanProcessor cria o batch span processor que alimenta exporter, com a
+// contagem de falhas de exportação e, conforme a configuração, a amostragem
+// que mantém erros e o filtro de caminhos ignorados.
+func wrapSpanProcessor(exporter trace.SpanExporter, cfg config.Config) trace.SpanProcessor {
+	exporter = failureCountingSpanExporter{SpanExporter: exporter}
+
+	var processor trace.SpanProcessor = trace.NewBatchSpanProcessor(exporter, batcherOptions()...)
+	if keepErrors() {
+		processor = newErrorKeepingProcessor(processor, cfg.SamplingRatio)
+	}
+	if paths := env.List("OTEL_TRACE_IGNORE_PATHS", nil); len(paths) > 0 {
+		processor = newPathFilterProcessor(processor, paths)
+	}
+	return processor
 }
 
-func newMeterProvider() (*metric.MeterProvider, error) {
-	metricExporter, err := stdoutmetric.New()
+// newTraceExporter cria o exporter OTLP/gRPC de traces para endpoint.
+func newTraceExporter(endpoint string, cfg config.Config) (trace.SpanExporter, error) {
+	parsed, err := parseEndpoint(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
+	otlpExporter, err := otlptracegrpc.New(context.Background(), traceExporterOptions(parsed, cfg.DialTimeout)...)
+	if err != nil {
+		log.Printf("❌ Erro ao criar OTLP exporter para %s: %v", endpoint, err)
+		return nil, err
+	}
+	return &connectionLogExporter{SpanExporter: otlpExporter, endpoint: endpoint}, nil
+}
+
+// signalEndpoint retorna o endpoint OTLP de um sinal (TRACES, METRICS ou
+// LOGS): OTEL_EXPORTER_OTLP_<SINAL>_ENDPOINT tem precedência sobre o endpoint
+// compartilhado.
+func signalEndpoint(signal, shared string) string {
+	return env.String("OTEL_EXPORTER_OTLP_"+signal+"_ENDPOINT", shared)
+}
+
+// batcherOptions lê a configuração do batch span processor das variáveis
+// OTEL_BSP_*. OTEL_BSP_SCHEDULE_DELAY aceita milissegundos ou uma duração do Go.
+func batcherOptions() []trace.BatchSpanProcessorOption {
+	queueSize := env.Int("OTEL_BSP_MAX_QUEUE_SIZE", trace.DefaultMaxQueueSize)
+	batchSize := env.Int("OTEL_BSP_MAX_EXPORT_BATCH_SIZE", trace.DefaultMaxExportBatchSize)
+	delay := env.Duration("OTEL_BSP_SCHEDULE_DELAY", time.Second)
+
+	if batchSize > queueSize {
+		log.Printf("⚠️ OTEL_BSP_MAX_EXPORT_BATCH_SIZE (%d) maior que OTEL_BSP_MAX_QUEUE_SIZE (%d): o lote será limitado ao tamanho da fila",
+			batchSize, queueSize)
+	}
+
+	return []trace.BatchSpanProcessorOption{
+		trace.WithMaxQueueSize(queueSize),
+		trace.WithMaxExportBatchSize(batchSize),
+		trace.WithBatchTimeout(delay),
+	}
+}
+
+// spanLimits limita atributos e eventos por span, para que um handler com bug
+// (por exemplo, capturando headers ou corpos enormes) não estoure o payload de
+// exportação. Os limites seguem as variáveis OTEL_SPAN_* da especificação, mas
+// o tamanho dos valores tem um teto de 4096 caracteres em vez de ilimitado.
+func spanLimits() trace.SpanLimits {
+	limits := trace.NewSpanLimits()
+	limits.AttributeCountLimit = env.Int("OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", trace.DefaultAttributeCountLimit)
+	limits.AttributeValueLengthLimit = env.Int("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", 4096)
+	limits.EventCountLimit = env.Int("OTEL_SPAN_EVENT_COUNT_LIMIT", trace.DefaultEventCountLimit)
+	return limits
+}
+
+// newMeterProvider cria o MeterProvider com o exporter escolhido em
+// OTEL_METRICS_EXPORTER: "stdout" (padrão), "otlp" ou "prometheus". No caso do
+// Prometheus, também retorna o handler de scrape que as apps devem expor.
+func newMeterProvider(res *resource.Resource, endpoint string) (*metric.MeterProvider, http.Handler, error) {
+	var reader metric.Reader
+	var handler http.Handler
+
+	switch exporter := env.String("OTEL_METRICS_EXPORTER", "stdout"); exporter {
+	case "stdout":
+		metricExporter, err := stdoutmetric.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = metric.NewPeriodicReader(failureCountingMetricExporter{Exporter: metricExporter},
+			metric.WithInterval(metricExportInterval()))
+	case "otlp":
+		parsed, err := parseEndpoint(endpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		metricExporter, err := otlpmetricgrpc.New(context.Background(), metricExporterOptions(parsed)...)
+		if err != nil {
+			log.Printf("❌ Erro ao criar OTLP metric exporter: %v", err)
+			return nil, nil, err
+		}
+		reader = metric.NewPeriodicReader(failureCountingMetricExporter{Exporter: metricExporter},
+			metric.WithInterval(metricExportInterval()))
+	case "prometheus":
+		// O exporter registra as métricas no registry padrão do Prometheus,
+		// servido pelo promhttp.Handler
+		promExporter, err := prometheus.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = promExporter
+		handler = promhttp.Handler()
+	default:
+		return nil, nil, fmt.Errorf("OTEL_METRICS_EXPORTER inválido: %q (use stdout, otlp ou prometheus)", exporter)
+	}
+
 	meterProvider := metric.NewMeterProvider(
-		metric.WithReader(metric.NewPeriodicReader(metricExporter,
-			metric.WithInterval(3*time.Second))),
+		metric.WithResource(res),
+		metric.WithReader(reader),
+		metric.WithView(metricViews()...),
 	)
-	return meterProvider, nil
+	return meterProvider, handler, nil
 }
 
-func newLoggerProvider() (*otellog.LoggerProvider, error) {
-	logExporter, err := stdoutlog.New()
-	if err != nil {
-		return nil, err
+// metricExportInterval lê OTEL_METRIC_EXPORT_INTERVAL, com o padrão de 60s
+// definido pela especificação.
+func metricExportInterval() time.Duration {
+	const defaultInterval = 60 * time.Second
+
+	interval := env.Duration("OTEL_METRIC_EXPORT_INTERVAL", defaultInterval)
+	if interval <= 0 {
+		log.Printf("⚠️ OTEL_METRIC_EXPORT_INTERVAL deve ser positivo (%s), usando padrão: %s", interval, defaultInterval)
+		interval = defaultInterval
+	}
+
+	log.Printf("📊 Intervalo de exportação de métricas: %s", interval)
+	return interval
+}
+
+// newLoggerProvider cria o LoggerProvider com o exporter escolhido em
+// OTEL_LOGS_EXPORTER: "stdout" (padrão) ou "otlp". Registros abaixo de
+// LOG_LEVEL são descartados antes do batch.
+func newLoggerProvider(res *resource.Resource, endpoint string) (*otellog.LoggerProvider, error) {
+	var logExporter otellog.Exporter
+
+	switch exporter := env.String("OTEL_LOGS_EXPORTER", "stdout"); exporter {
+	case "stdout":
+		stdoutExporter, err := stdoutlog.New()
+		if err != nil {
+			return nil, err
+		}
+		logExporter = stdoutExporter
+	case "otlp":
+		parsed, err := parseEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		otlpExporter, err := otlploggrpc.New(context.Background(), logExporterOptions(parsed)...)
+		if err != nil {
+			log.Printf("❌ Erro ao criar OTLP log exporter: %v", err)
+			return nil, err
+		}
+		logExporter = otlpExporter
+	default:
+		return nil, fmt.Errorf("OTEL_LOGS_EXPORTER inválido: %q (use stdout ou otlp)", exporter)
 	}
 
 	loggerProvider := otellog.NewLoggerProvider(
-		otellog.WithProcessor(otellog.NewBatchProcessor(logExporter)),
+		otellog.WithResource(res),
+		otellog.WithProcessor(newMinSeverityProcessor(
+			otellog.NewBatchProcessor(failureCountingLogExporter{Exporter: logExporter}),
+			logLevel(),
+		)),
 	)
 	return loggerProvider, nil
 }
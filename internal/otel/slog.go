@@ -0,0 +1,112 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+
+	"go-observability-lab/internal/env"
+
+	"go.opentelemetry.io/contrib/bridges/otelslog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewLogger cria um *slog.Logger que envia cada registro pelo pipeline de logs
+// do OpenTelemetry (correlacionado ao span do contexto pela bridge otelslog) e
+// também o escreve no stdout. O formato do stdout é definido por LOG_FORMAT:
+// "text" (padrão, legível em dev) ou "json". LOG_LEVEL define o nível mínimo
+// nos dois destinos.
+//
+// Registros feitos com um contexto com span recebem também trace_id e span_id
+// como atributos (veja TraceAttrs), para que a correlação funcione mesmo em
+// backends que não a fazem a partir dos campos próprios do registro OTel.
+func NewLogger(serviceName string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: logLevel()}
+
+	var stdout slog.Handler
+	if env.String("LOG_FORMAT", "text") == "json" {
+		stdout = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		stdout = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	return slog.New(traceContextHandler{fanoutHandler{
+		otelslog.NewHandler(serviceName),
+		stdout.WithAttrs([]slog.Attr{slog.String("service", serviceName)}),
+	}})
+}
+
+// TraceAttrs retorna trace_id e span_id do span de ctx como atributos de log,
+// ou nil quando ctx não tem span válido. Útil também com loggers que não foram
+// criados por NewLogger.
+func TraceAttrs(ctx context.Context) []slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []slog.Attr{
+		slog.String("trace_id", sc.TraceID().String()),
+		slog.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// traceContextHandler adiciona TraceAttrs a cada registro. Loggers com
+// WithGroup recebem os campos dentro do grupo, como qualquer outro atributo.
+type traceContextHandler struct {
+	slog.Handler
+}
+
+func (h traceContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := TraceAttrs(ctx); attrs != nil {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h traceContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceContextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceContextHandler) WithGroup(name string) slog.Handler {
+	return traceContextHandler{h.Handler.WithGroup(name)}
+}
+
+// fanoutHandler repassa cada registro para todos os handlers habilitados.
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var err error
+	for _, h := range f {
+		if h.Enabled(ctx, record.Level) {
+			err = errors.Join(err, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return err
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(f))
+	for i, h := range f {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
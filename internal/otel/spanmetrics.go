@@ -0,0 +1,68 @@
+package otel
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// spanMetricsProcessor deriva métricas RED (taxa, erros e duração) dos spans
+// de servidor que terminam, sem instrumentação nos handlers: cada span
+// incrementa span.server.calls e registra sua duração em span.server.duration,
+// ambos por span.name e status.code. Erros são as séries com
+// status.code="error".
+//
+// Só spans gravados chegam aos processors: com uma razão de amostragem abaixo
+// de 1, as métricas refletem apenas a fração amostrada, a menos que
+// OTEL_TRACES_KEEP_ERRORS=true, que grava todos os spans.
+type spanMetricsProcessor struct {
+	calls    metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func newSpanMetricsProcessor() *spanMetricsProcessor {
+	// O meter global passa a exportar assim que o MeterProvider é instalado,
+	// logo depois do TracerProvider
+	meter := otel.Meter("go-observability-lab/internal/otel")
+
+	calls, err := meter.Int64Counter("span.server.calls",
+		metric.WithDescription("Spans de servidor finalizados, por nome e status"),
+		metric.WithUnit("{span}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	duration, err := meter.Float64Histogram("span.server.duration",
+		metric.WithDescription("Duração dos spans de servidor, por nome e status"),
+		metric.WithUnit("s"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &spanMetricsProcessor{calls: calls, duration: duration}
+}
+
+func (p *spanMetricsProcessor) OnStart(context.Context, trace.ReadWriteSpan) {}
+
+func (p *spanMetricsProcessor) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanKind() != oteltrace.SpanKindServer {
+		return
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("span.name", s.Name()),
+		attribute.String("status.code", strings.ToLower(s.Status().Code.String())),
+	)
+	ctx := context.Background()
+	p.calls.Add(ctx, 1, attrs)
+	p.duration.Record(ctx, s.EndTime().Sub(s.StartTime()).Seconds(), attrs)
+}
+
+func (p *spanMetricsProcessor) Shutdown(context.Context) error { return nil }
+
+func (p *spanMetricsProcessor) ForceFlush(context.Context) error { return nil }
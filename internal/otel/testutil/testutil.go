@@ -0,0 +1,66 @@
+// Package testutil instala um pipeline de traces em memória para testes de
+// integração, permitindo inspecionar os spans exportados pelas apps.
+//
+// Como o TracerProvider é global, o uso típico é criar os providers uma vez em
+// TestMain:
+//
+//	var providers *testutil.TestProviders
+//
+//	func TestMain(m *testing.M) {
+//		providers = testutil.NewTestProviders()
+//		code := m.Run()
+//		providers.Shutdown(context.Background())
+//		os.Exit(code)
+//	}
+package testutil
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestProviders agrupa o TracerProvider de teste e o exporter em memória que
+// recebe os spans.
+type TestProviders struct {
+	TracerProvider *trace.TracerProvider
+	Exporter       *tracetest.InMemoryExporter
+}
+
+// NewTestProviders cria um TracerProvider que amostra todos os spans e os
+// exporta de forma síncrona para um InMemoryExporter, instalando-o (junto com
+// os propagadores W3C) globalmente.
+func NewTestProviders() *TestProviders {
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := trace.NewTracerProvider(
+		trace.WithSampler(trace.AlwaysSample()),
+		trace.WithSyncer(exporter),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return &TestProviders{TracerProvider: tracerProvider, Exporter: exporter}
+}
+
+// Spans força o flush do provider e retorna os spans exportados até agora.
+func (p *TestProviders) Spans(ctx context.Context) tracetest.SpanStubs {
+	_ = p.TracerProvider.ForceFlush(ctx)
+	return p.Exporter.GetSpans()
+}
+
+// Reset descarta os spans já registrados, isolando um teste do anterior.
+func (p *TestProviders) Reset() {
+	p.Exporter.Reset()
+}
+
+// Shutdown encerra o TracerProvider de teste.
+func (p *TestProviders) Shutdown(ctx context.Context) error {
+	return p.TracerProvider.Shutdown(ctx)
+}
@@ -0,0 +1,111 @@
+package otel
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go-observability-lab/internal/buildinfo"
+	"go-observability-lab/internal/env"
+	"go-observability-lab/internal/semconv"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric"
+)
+
+// requestDurationInstrument é o histograma de duração registrado pelo otelhttp.
+const requestDurationInstrument = "http.server.request.duration"
+
+// defaultDurationBuckets segue as fronteiras dos nossos SLOs, em segundos
+// (a unidade do instrumento): 50ms, 100ms, 250ms, 500ms e 1s.
+var defaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1}
+
+// versionMetricAttribute indica se service.version deve virar dimensão de
+// http.server.request.duration (METRICS_SERVICE_VERSION_ATTRIBUTE, desligado
+// por padrão). Como resource attributes não viram labels em todos os backends,
+// é o que permite comparar a latência entre deploys; fica desligado para não
+// multiplicar as séries a cada versão.
+func versionMetricAttribute() bool {
+	return env.Bool("METRICS_SERVICE_VERSION_ATTRIBUTE", false)
+}
+
+// ServerHandlerOptions retorna as opções do otelhttp.NewHandler das apps. Com
+// METRICS_SERVICE_VERSION_ATTRIBUTE=true, as métricas do servidor recebem
+// service.version; as views mantêm o atributo só no histograma de duração.
+func ServerHandlerOptions() []otelhttp.Option {
+	if !versionMetricAttribute() {
+		return nil
+	}
+
+	version := semconv.ServiceVersionKey.String(buildinfo.ServiceVersion())
+	return []otelhttp.Option{
+		otelhttp.WithMetricAttributesFn(func(*http.Request) []attribute.KeyValue {
+			return []attribute.KeyValue{version}
+		}),
+	}
+}
+
+// metricViews retorna as views aplicadas ao MeterProvider.
+func metricViews() []metric.View {
+	views := []metric.View{
+		metric.NewView(
+			metric.Instrument{Name: requestDurationInstrument},
+			metric.Stream{Aggregation: durationAggregation()},
+		),
+	}
+
+	if versionMetricAttribute() {
+		// Os demais instrumentos do otelhttp também recebem service.version, que
+		// só é útil na duração
+		withoutVersion := metric.Stream{AttributeFilter: attribute.NewDenyKeysFilter(semconv.ServiceVersionKey)}
+		views = append(views,
+			metric.NewView(metric.Instrument{Name: "http.server.request.body.size"}, withoutVersion),
+			metric.NewView(metric.Instrument{Name: "http.server.response.body.size"}, withoutVersion),
+		)
+	}
+	return views
+}
+
+// durationAggregation lê HISTOGRAM_TYPE: "explicit" (o padrão) usa as
+// fronteiras de durationBuckets; "exponential" usa buckets exponenciais de
+// base 2, que se ajustam à distribuição observada e dão percentis mais
+// precisos com poucas séries, em backends que os suportam.
+func durationAggregation() metric.Aggregation {
+	switch histogramType := env.String("HISTOGRAM_TYPE", "explicit"); histogramType {
+	case "explicit":
+		return metric.AggregationExplicitBucketHistogram{Boundaries: durationBuckets()}
+	case "exponential":
+		// Os mesmos limites padrão do SDK para este tipo de agregação
+		return metric.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}
+	default:
+		log.Printf("⚠️ HISTOGRAM_TYPE inválido (%q), usando explicit", histogramType)
+		return metric.AggregationExplicitBucketHistogram{Boundaries: durationBuckets()}
+	}
+}
+
+// durationBuckets lê as fronteiras de HTTP_SERVER_DURATION_BUCKETS (segundos,
+// separados por vírgula e em ordem crescente).
+func durationBuckets() []float64 {
+	values := env.List("HTTP_SERVER_DURATION_BUCKETS", nil)
+	if len(values) == 0 {
+		return defaultDurationBuckets
+	}
+
+	buckets := make([]float64, 0, len(values))
+	for _, v := range values {
+		b, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			log.Printf("⚠️ HTTP_SERVER_DURATION_BUCKETS inválido (%q), usando padrão: %v", v, defaultDurationBuckets)
+			return defaultDurationBuckets
+		}
+		buckets = append(buckets, b)
+	}
+
+	if !slices.IsSorted(buckets) || len(slices.Compact(slices.Clone(buckets))) != len(buckets) {
+		log.Printf("⚠️ HTTP_SERVER_DURATION_BUCKETS deve estar em ordem estritamente crescente, usando padrão: %v", defaultDurationBuckets)
+		return defaultDurationBuckets
+	}
+	return buckets
+}
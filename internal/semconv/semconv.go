@@ -0,0 +1,38 @@
+// Package semconv isola a versão das convenções semânticas usada pelo lab:
+// para trocar de versão, basta mudar o import abaixo. Só as chaves usadas no
+// código são reexportadas.
+package semconv
+
+import (
+	"go-observability-lab/internal/env"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.28.0"
+)
+
+// Chaves de resource.
+const (
+	ServiceNameKey       = semconv.ServiceNameKey
+	ServiceVersionKey    = semconv.ServiceVersionKey
+	ServiceInstanceIDKey = semconv.ServiceInstanceIDKey
+	ServiceNamespaceKey  = semconv.ServiceNamespaceKey
+	K8SPodNameKey        = semconv.K8SPodNameKey
+	K8SNamespaceNameKey  = semconv.K8SNamespaceNameKey
+	K8SNodeNameKey       = semconv.K8SNodeNameKey
+)
+
+// Chaves de URL.
+const (
+	URLPathKey  = semconv.URLPathKey
+	URLFullKey  = semconv.URLFullKey
+	URLQueryKey = semconv.URLQueryKey
+)
+
+// SchemaURL retorna o schema URL da versão em uso, ou "" com
+// OTEL_SEMCONV_OMIT_SCHEMA_URL=true, para backends que rejeitam schemas que
+// não conhecem. Sem schema URL, o resource é criado sem schema.
+func SchemaURL() string {
+	if env.Bool("OTEL_SEMCONV_OMIT_SCHEMA_URL", false) {
+		return ""
+	}
+	return semconv.SchemaURL
+}
@@ -0,0 +1,33 @@
+// Package server reúne a configuração comum dos servidores HTTP das apps.
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"go-observability-lab/internal/env"
+)
+
+// ListenAddr resolve o endereço de escuta a partir de LISTEN_ADDR (host:porta)
+// ou, na ausência dele, de PORT. Retorna def quando nenhuma das duas está
+// definida e um erro quando o endereço resultante não é válido.
+func ListenAddr(def string) (string, error) {
+	addr := env.String("LISTEN_ADDR", "")
+	if addr == "" {
+		if port := env.String("PORT", ""); port != "" {
+			addr = ":" + port
+		} else {
+			addr = def
+		}
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("endereço de escuta inválido %q: %w", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", fmt.Errorf("endereço de escuta inválido %q: porta %q fora do intervalo", addr, port)
+	}
+	return addr, nil
+}
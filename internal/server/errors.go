@@ -0,0 +1,35 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorResponse é o corpo JSON das respostas de erro das apps. O trace ID
+// permite que o cliente informe ao suporte qual trace investigar.
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+// WriteErrorJSON responde com status e um ErrorResponse com a mensagem de err,
+// um código derivado do status (por exemplo, "internal_server_error") e o
+// trace ID da requisição.
+func WriteErrorJSON(w http.ResponseWriter, r *http.Request, status int, err error) {
+	resp := ErrorResponse{
+		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Message: err.Error(),
+	}
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		resp.TraceID = sc.TraceID().String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
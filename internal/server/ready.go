@@ -0,0 +1,41 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go-observability-lab/internal/env"
+)
+
+// draining indica que a app recebeu o sinal de parada e está em drain.
+var draining atomic.Bool
+
+// HandleReady responde à sonda de readiness: 200 enquanto a app aceita
+// tráfego e 503 a partir do início de Drain, para que o load balancer pare de
+// enviar requisições.
+func HandleReady(w http.ResponseWriter, r *http.Request) {
+	if draining.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("DRAINING"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("READY"))
+}
+
+// Drain marca a app como não pronta e espera PRESTOP_DRAIN (padrão 0, sem
+// espera), continuando a atender as requisições em andamento e as novas
+// enquanto o load balancer remove a instância. Deve ser chamada entre o sinal
+// de parada e Shutdown.
+func Drain() {
+	draining.Store(true)
+
+	delay := env.Duration("PRESTOP_DRAIN", 0)
+	if delay <= 0 {
+		return
+	}
+	log.Printf("🚰 Drenando por %s antes de encerrar o servidor", delay)
+	time.Sleep(delay)
+}
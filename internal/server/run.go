@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Run atende handler em addr até ctx ser cancelado, normalmente pelo sinal de
+// parada, ou o servidor falhar. No cancelamento, a app entra em Drain e só
+// então as requisições em andamento são canceladas, para que continuem sendo
+// atendidas enquanto o load balancer remove a instância. Em ambos os casos,
+// termina com Shutdown dentro de timeout, drenando o servidor antes de enviar
+// e encerrar a telemetria, para que os spans das requisições não se percam.
+func Run(ctx context.Context, name, addr string, handler http.Handler, telemetry Telemetry, timeout time.Duration) error {
+	reqCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	srv := New(reqCtx, addr, handler)
+
+	srvErr := make(chan error, 1)
+	go func() {
+		log.Printf("🚀 %s iniciado em %s", name, addr)
+		srvErr <- srv.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-srvErr:
+	case <-ctx.Done():
+		Drain()
+		cancelRequests()
+	}
+
+	return errors.Join(err, Shutdown(srv, telemetry, timeout))
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunFlushesOnCancel(t *testing.T) {
+	t.Setenv("PRESTOP_DRAIN", "0")
+
+	var got calls
+	telemetry := fakeTelemetry{calls: &got}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, "test", "127.0.0.1:0", http.NotFoundHandler(), telemetry, time.Second)
+	}()
+
+	// Cancela o contexto como o sinal de parada faria
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run não retornou após o cancelamento do contexto")
+	}
+
+	want := calls{"ForceFlush", "Shutdown"}
+	if !slices.Equal(got, want) {
+		t.Errorf("etapas da telemetria = %v, esperado %v", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"go-observability-lab/internal/env"
+)
+
+// New cria o servidor HTTP das apps com os timeouts comuns
+// (SERVER_READ_TIMEOUT, padrão 1s, e SERVER_WRITE_TIMEOUT, padrão 10s). ctx é
+// o contexto base das requisições, cancelado no encerramento da app.
+func New(ctx context.Context, addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		BaseContext:  func(_ net.Listener) context.Context { return ctx },
+		ReadTimeout:  env.Duration("SERVER_READ_TIMEOUT", time.Second),
+		WriteTimeout: env.Duration("SERVER_WRITE_TIMEOUT", 10*time.Second),
+		Handler:      handler,
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Stopper é o servidor a ser drenado; *http.Server o implementa.
+type Stopper interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Telemetry é a telemetria a ser enviada e encerrada depois do servidor;
+// *otel.Providers a implementa.
+type Telemetry interface {
+	ForceFlush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown encerra a app em uma ordem determinística, dentro de um único
+// prazo: primeiro drena o servidor, para que as requisições em andamento
+// terminem seus spans; depois força o flush da telemetria; por fim, encerra os
+// providers. Uma etapa que falha não impede as seguintes.
+func Shutdown(srv Stopper, telemetry Telemetry, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	srvErr := srv.Shutdown(ctx)
+
+	start := time.Now()
+	flushErr := telemetry.ForceFlush(ctx)
+	log.Printf("📤 Telemetria enviada em %s", time.Since(start))
+
+	return errors.Join(srvErr, flushErr, telemetry.Shutdown(ctx))
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// calls registra, em ordem, as etapas do encerramento executadas pelos fakes.
+type calls []string
+
+type fakeStopper struct {
+	calls *calls
+	err   error
+}
+
+func (s fakeStopper) Shutdown(context.Context) error {
+	*s.calls = append(*s.calls, "srv.Shutdown")
+	return s.err
+}
+
+type fakeTelemetry struct {
+	calls       *calls
+	flushErr    error
+	shutdownErr error
+}
+
+func (t fakeTelemetry) ForceFlush(context.Context) error {
+	*t.calls = append(*t.calls, "ForceFlush")
+	return t.flushErr
+}
+
+func (t fakeTelemetry) Shutdown(context.Context) error {
+	*t.calls = append(*t.calls, "Shutdown")
+	return t.shutdownErr
+}
+
+func TestShutdownOrder(t *testing.T) {
+	var got calls
+	err := Shutdown(fakeStopper{calls: &got}, fakeTelemetry{calls: &got}, time.Second)
+	if err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	want := calls{"srv.Shutdown", "ForceFlush", "Shutdown"}
+	if !slices.Equal(got, want) {
+		t.Errorf("etapas = %v, esperado %v", got, want)
+	}
+}
+
+func TestShutdownJoinsErrors(t *testing.T) {
+	srvErr := errors.New("servidor")
+	flushErr := errors.New("flush")
+	shutdownErr := errors.New("providers")
+
+	var got calls
+	err := Shutdown(
+		fakeStopper{calls: &got, err: srvErr},
+		fakeTelemetry{calls: &got, flushErr: flushErr, shutdownErr: shutdownErr},
+		time.Second,
+	)
+
+	// A falha do servidor não interrompe nem substitui as etapas seguintes
+	if len(got) != 3 {
+		t.Errorf("etapas = %v, esperado as 3 etapas", got)
+	}
+	for _, want := range []error{srvErr, flushErr, shutdownErr} {
+		if !errors.Is(err, want) {
+			t.Errorf("err = %v, esperado conter %v", err, want)
+		}
+	}
+}